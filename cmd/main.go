@@ -1,88 +1,48 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+
 	assetpack "spooknloot"
-	"spooknloot/pkg/boss"
-	"spooknloot/pkg/debug"
-	"spooknloot/pkg/dungeon"
-	"spooknloot/pkg/mobs"
-	"spooknloot/pkg/player"
-	"spooknloot/pkg/ui"
-	"spooknloot/pkg/world"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/game"
+	"spooknloot/pkg/replay"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/settings"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	screenWidth               = 1500
-	screenHeight              = 900
-	exitCooldownFramesDefault = 20
+	// Longest frame we still try to catch up on; anything slower (window
+	// dragged, debugger) is dropped instead of spiralling.
+	maxFrameTime float32 = 0.25
 )
 
 var (
-	running        = true
-	worldBgColor   = rl.NewColor(143, 77, 87, 1)
-	dungeonBgColor = rl.NewColor(41, 29, 43, 1)
-
-	musicPaused  bool
-	worldMusic   rl.Music
-	dungeonMusic rl.Music
-	bossMusic    rl.Music
-	currentMusic string
-	printDebug   bool
-
-	menuOpen        bool = true
-	menuPausedMusic bool
+	running = true
 
-	inDungeon           bool
-	inBoss              bool
-	dungeonsCleared     int
-	savedWorldPos       rl.Vector2
-	exitCooldownFrames  int
-	dungeonSpawnCount   int
-	dungeonSpawnBaseMin int = 5
-	dungeonSpawnBaseMax int = 10
-	exitSoundPlayed     bool
-	mobsClearFrames     int
+	recordPath = flag.String("record", "", "record this session's input to a replay file")
+	replayPath = flag.String("replay", "", "play back a replay file instead of reading input")
 
-	bossWinOpen bool
+	recorder *replay.Recorder
+	playback *replay.Playback
 )
 
-func drawScene() {
-	if inBoss {
-		boss.Draw()
-		mobs.DrawMobs()
-		dungeon.DrawPotion()
-		mobs.SpawnMobs(20, "random", boss.FloorTiles)
-		dungeon.SpawnPotions(5, boss.FloorTiles, boss.BossMap.TileSize)
-	} else if inDungeon {
-		dungeon.Draw()
-		mobs.DrawMobs()
-	} else {
-		world.DrawWorld()
-		world.DrawBottomLamp()
-		world.DrawDoors()
-		mobs.DrawMobs()
-		world.DrawPumpkinLamp()
-	}
-
-	player.DrawPlayerTexture()
-
-	if !inDungeon && !inBoss {
-		world.DrawWheat()
-		world.DrawTopLamp()
-		world.DrawCauldron()
-		mobs.SpawnMobs(8, "random", world.Spawn)
-
-	}
-
-	if printDebug {
-		debug.DrawPlayerOutlines()
-	}
-}
-
 func init() {
+	settings.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+	if err := settings.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, "loading settings:", err)
+	}
+	settings.ApplyFlags()
+	// Assets are extracted to a temporary directory below, which changes
+	// the working directory
+	*recordPath = absPath(*recordPath)
+	*replayPath = absPath(*replayPath)
 
 	// Prepare embedded assets (for single-file distribution). This extracts
 	// assets to a temporary directory and switches CWD so existing relative
@@ -93,8 +53,8 @@ func init() {
 	monW := rl.GetMonitorWidth(monitor)
 	monH := rl.GetMonitorHeight(monitor)
 
-	winW := int32(screenWidth)
-	winH := int32(screenHeight)
+	winW := int32(settings.Current.Width)
+	winH := int32(settings.Current.Height)
 	if monW > 0 && int32(monW) < winW {
 		winW = int32(monW)
 	}
@@ -104,500 +64,123 @@ func init() {
 
 	rl.InitWindow(winW, winH, "spook 'n loot - a game by joeel56")
 
-	rl.SetExitKey(0)
-	rl.SetTargetFPS(60)
-
-	rl.InitAudioDevice()
-
-	if _, err := os.Stat("assets/audio/world.mp3"); err == nil {
-		worldMusic = rl.LoadMusicStream("assets/audio/world.mp3")
-		rl.SetMusicVolume(worldMusic, 0.2)
-	}
-	if _, err := os.Stat("assets/audio/dungeon.mp3"); err == nil {
-		dungeonMusic = rl.LoadMusicStream("assets/audio/dungeon.mp3")
-		rl.SetMusicVolume(dungeonMusic, 0.6)
-	}
-	if _, err := os.Stat("assets/audio/boss.mp3"); err == nil {
-		bossMusic = rl.LoadMusicStream("assets/audio/boss.mp3")
-		rl.SetMusicVolume(bossMusic, 0.6)
-	}
-
-	world.InitWorld()
-	world.InitDoors()
-	world.InitLamps()
-	world.InitPumpkinLamps()
-
-	world.LoadMap("pkg/world/map.json")
-
-	player.InitPlayer()
-	mobs.InitMobs()
-
-	dungeon.Init()
-	boss.Init()
-	boss.LoadMap("pkg/boss/map.json")
-
-	printDebug = false
-
-	playTrack("world")
-
-	ui.InitMenu("assets/ui/map.png")
-}
-
-func input() {
-	if rl.IsKeyPressed(rl.KeyF10) {
+	if settings.Current.Fullscreen {
 		rl.ToggleBorderlessWindowed()
 	}
 
-	if rl.IsKeyPressed(rl.KeyB) {
-		enterBoss()
+	rl.SetExitKey(0)
+	// Simulation runs at a fixed rate, so rendering may go as fast as the display
+	refresh := rl.GetMonitorRefreshRate(monitor)
+	if refresh <= 0 {
+		refresh = game.TickRate
 	}
+	rl.SetTargetFPS(int32(refresh))
 
-	if rl.IsKeyPressed(rl.KeyF7) {
-		musicPaused = !musicPaused
-		if musicPaused {
-			pauseCurrentMusic()
-		} else {
-			resumeCurrentMusic()
-		}
-	}
+	rl.InitAudioDevice()
 
-	if menuOpen {
-		if rl.IsKeyPressed(rl.KeyEnter) || rl.IsKeyPressed(rl.KeyEscape) {
-			menuOpen = false
-			if menuPausedMusic {
-				resumeCurrentMusic()
-				menuPausedMusic = false
-			}
+	seed := settings.Current.Seed
+	if *replayPath != "" {
+		p, err := replay.Load(*replayPath, backend.Current)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "loading replay:", err)
+			os.Exit(1)
 		}
-	} else if !bossWinOpen {
-		if rl.IsKeyPressed(rl.KeyEscape) {
-			menuOpen = true
-			if !musicPaused {
-				pauseCurrentMusic()
-				menuPausedMusic = true
-			}
-		}
-	}
-
-	if bossWinOpen {
-		if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-			mp := rl.GetMousePosition()
-			b := ui.GetBossWinButtonRect()
-			if mp.X >= b.X && mp.X <= b.X+b.Width && mp.Y >= b.Y && mp.Y <= b.Y+b.Height {
-
-				mobs.ResetMobs()
-				bossWinOpen = false
-			}
+		playback = p
+		seed = p.Seed()
+		backend.Use(p)
+	} else if *recordPath != "" {
+		// A replay only works if every run of the session uses the same seed
+		if seed == 0 {
+			seed = rng.NewSeed()
 		}
-		return
+		recorder = replay.NewRecorder(backend.Current, seed, settings.Current.Bindings)
+		backend.Use(recorder)
 	}
 
-	if !menuOpen {
-		player.PlayerInput()
-	}
-
-	if rl.IsKeyPressed(rl.KeyF3) {
-		printDebug = !printDebug
+	// Replays must not depend on or overwrite the player's save
+	game.SetSaving(playback == nil && recorder == nil)
+	if playback != nil {
+		game.SetBindings(playback.Bindings())
 	}
+	game.SetSeed(seed)
+	game.Init()
 }
 
-func update() {
-	running = !rl.WindowShouldClose()
-
-	updateCurrentMusic()
-
-	if menuOpen {
-		return
-	}
-
-	if !inDungeon && !inBoss {
-		world.LightLamps()
-		world.LightPumpkinLamps()
-	}
-
-	if player.IsPlayerDead() {
-		player.PlayerMoving()
-		if player.HasPlayerDeathAnimationFinished() {
-			if inDungeon {
-				exitDungeon()
-			} else if inBoss {
-				exitBoss()
-			}
-			dungeonsCleared = 0
-			dungeonSpawnCount = 0
-
-			mobs.ResetMobs()
-			player.ResetPlayer()
-		}
-		return
-	}
-
-	player.PlayerMoving()
-
-	playerPos := rl.NewVector2(player.PlayerHitBox.X+(player.PlayerHitBox.Width/2), player.PlayerHitBox.Y+(player.PlayerHitBox.Height/2))
-	attackPlayerFunc := func() {
-		player.SetPlayerDamageState()
-		player.TakeDamage(0.3)
+func absPath(path string) string {
+	if path == "" {
+		return ""
 	}
-	if inDungeon {
-		mobs.MobMoving(playerPos, attackPlayerFunc)
-		dungeon.UpdatePotionPickup(player.PlayerHitBox)
-	} else if !inBoss {
-		mobs.MobMoving(playerPos, attackPlayerFunc)
-	} else if inBoss {
-		attackPlayerFuncBoss := func() {
-			player.SetPlayerDamageState()
-			player.TakeDamage(0.6)
-		}
-		mobs.MobMoving(playerPos, attackPlayerFuncBoss)
-		dungeon.UpdatePotionPickup(player.PlayerHitBox)
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
 	}
+	return path
+}
 
-	if mobs.IsMobAlive() {
-		closestMobIndex := mobs.GetClosestMobIndex(playerPos)
-		if closestMobIndex != -1 {
-			mobCenter := mobs.GetMobHitboxCenterByIndex(closestMobIndex)
-			player.TryAttack(mobCenter, func(damage float32) {
-				mobs.DamageMob(closestMobIndex, damage)
-			})
-		}
-	}
-
-	if !inDungeon && !inBoss {
-		checkEnterDungeon()
-	} else if inDungeon {
-		if exitCooldownFrames > 0 {
-			exitCooldownFrames--
-		}
-
-		if mobs.IsMobAlive() {
-			dungeon.HideExit()
-			exitSoundPlayed = false
-			mobsClearFrames = 0
-		} else {
-			if mobsClearFrames < 6 {
-				mobsClearFrames++
-			}
-			if mobsClearFrames >= 6 {
-				dungeon.ShowExit()
-				if !exitSoundPlayed {
-					world.PlayDoorOpenSound()
-					exitSoundPlayed = true
-				}
-			}
-		}
-
-		if exitCooldownFrames <= 0 && !mobs.IsMobAlive() && dungeon.IsPlayerAtExit(player.PlayerHitBox) {
-			dungeonsCleared++
-			if dungeonsCleared >= 20 {
-				enterBoss()
-			} else {
-				nextDungeon()
-			}
-		}
-	} else if inBoss {
-
-		if !mobs.IsBossAlive() {
-			exitBoss()
-			bossWinOpen = true
-		}
-	}
+func input() {
+	game.HandleInput()
 }
 
-func render() {
-	var cam = player.Cam
+func update() {
+	game.Update(game.TickDelta)
+}
 
+func render(alpha float32) {
 	rl.BeginDrawing()
-	if inDungeon || inBoss {
-		rl.ClearBackground(dungeonBgColor)
-	} else {
-		rl.ClearBackground(worldBgColor)
-	}
-	rl.BeginMode2D(cam)
-
-	drawScene()
-	rl.EndMode2D()
-
-	player.DrawHealthBar()
 
-	if bossWinOpen {
-		ui.DrawBossWinOverlay()
-	}
-
-	if inBoss && mobs.IsBossAlive() {
-		if current, max, ok := mobs.GetBossHealth(); ok && max > 0 {
-			percent := current / max
-			if percent < 0 {
-				percent = 0
-			}
-			barW := float32(480)
-			barH := float32(20)
-			barX := float32(screenWidth)/2 - barW/2
-			barY := float32(16)
-			bg := rl.NewRectangle(barX, barY, barW, barH)
-			fg := rl.NewRectangle(barX+2, barY+2, (barW-4)*percent, barH-4)
-			rl.DrawRectangleRec(bg, rl.NewColor(0, 0, 0, 200))
-			color := rl.Color{R: 190, G: 75, B: 75, A: 255}
-			if percent <= 0.2 {
-				color = rl.Color{R: 57, G: 108, B: 60, A: 255}
-			} else if percent <= 0.5 {
-				color = rl.Color{R: 231, G: 152, B: 50, A: 255}
-			}
-			rl.DrawRectangleRec(fg, color)
-		}
-	}
-
-	if printDebug {
-		debug.DrawDebug(debug.DebugText())
-	}
-
-	if menuOpen {
-		ui.DrawMenuOverlay()
-	}
+	game.Draw(alpha)
 
 	rl.EndDrawing()
 }
 
 func quit() {
-	stopAllTracks()
-	if worldMusic.CtxType != 0 {
-		rl.UnloadMusicStream(worldMusic)
-	}
-	if dungeonMusic.CtxType != 0 {
-		rl.UnloadMusicStream(dungeonMusic)
-	}
-	if bossMusic.CtxType != 0 {
-		rl.UnloadMusicStream(bossMusic)
+	if recorder != nil {
+		if err := recorder.Save(*recordPath); err != nil {
+			fmt.Fprintln(os.Stderr, "saving replay:", err)
+		}
 	}
+
+	game.Unload()
 	rl.CloseAudioDevice()
-	player.UnloadPlayerTexture()
-	world.UnloadWorldTexture()
-	world.UnloadDoorsTextures()
-	world.UnloadPumpkinLamps()
-	mobs.UnloadMobsTexture()
-	dungeon.Unload()
-	ui.UnloadMenu()
 
 	rl.CloseWindow()
 }
 
 func main() {
+	var accumulator float32
 	for running {
-		input()
-		update()
-		render()
-	}
+		running = !rl.WindowShouldClose() && !game.QuitRequested()
 
-	quit()
-}
-
-func playTrack(which string) {
-	if currentMusic == which {
-		return
-	}
-	stopAllTracks()
-	switch which {
-	case "dungeon":
-		if dungeonMusic.CtxType != 0 {
-			rl.PlayMusicStream(dungeonMusic)
-			if musicPaused {
-				rl.PauseMusicStream(dungeonMusic)
+		if playback != nil {
+			// Replays run exactly the ticks that were recorded for each frame
+			if !playback.NextFrame() {
+				break
 			}
-		}
-	case "boss":
-		if bossMusic.CtxType != 0 {
-			rl.PlayMusicStream(bossMusic)
-			if musicPaused {
-				rl.PauseMusicStream(bossMusic)
-			}
-		}
-	default:
-		if worldMusic.CtxType != 0 {
-			rl.PlayMusicStream(worldMusic)
-			if musicPaused {
-				rl.PauseMusicStream(worldMusic)
+			input()
+			for i := 0; i < playback.Ticks(); i++ {
+				update()
 			}
+			render(1)
+			continue
 		}
-		which = "world"
-	}
-	currentMusic = which
-}
-
-func stopAllTracks() {
-	if worldMusic.CtxType != 0 {
-		rl.StopMusicStream(worldMusic)
-	}
-	if dungeonMusic.CtxType != 0 {
-		rl.StopMusicStream(dungeonMusic)
-	}
-	if bossMusic.CtxType != 0 {
-		rl.StopMusicStream(bossMusic)
-	}
-}
-
-func updateCurrentMusic() {
-	switch currentMusic {
-	case "dungeon":
-		if dungeonMusic.CtxType != 0 {
-			rl.UpdateMusicStream(dungeonMusic)
-		}
-	case "boss":
-		if bossMusic.CtxType != 0 {
-			rl.UpdateMusicStream(bossMusic)
-		}
-	case "world":
-		if worldMusic.CtxType != 0 {
-			rl.UpdateMusicStream(worldMusic)
-		}
-	}
-}
-
-func pauseCurrentMusic() {
-	switch currentMusic {
-	case "dungeon":
-		if dungeonMusic.CtxType != 0 {
-			rl.PauseMusicStream(dungeonMusic)
-		}
-	case "boss":
-		if bossMusic.CtxType != 0 {
-			rl.PauseMusicStream(bossMusic)
-		}
-	case "world":
-		if worldMusic.CtxType != 0 {
-			rl.PauseMusicStream(worldMusic)
-		}
-	}
-}
 
-func resumeCurrentMusic() {
-	switch currentMusic {
-	case "dungeon":
-		if dungeonMusic.CtxType != 0 {
-			rl.ResumeMusicStream(dungeonMusic)
-		}
-	case "boss":
-		if bossMusic.CtxType != 0 {
-			rl.ResumeMusicStream(bossMusic)
-		}
-	case "world":
-		if worldMusic.CtxType != 0 {
-			rl.ResumeMusicStream(worldMusic)
+		frameTime := rl.GetFrameTime()
+		if frameTime > maxFrameTime {
+			frameTime = maxFrameTime
 		}
-	}
-}
-
-func checkEnterDungeon() {
-	if player.PlayerHitBox.X < float32(world.HouseDoorDest.X+world.HouseDoorDest.Width) &&
-		player.PlayerHitBox.X+player.PlayerHitBox.Width > float32(world.HouseDoorDest.X) &&
-		player.PlayerHitBox.Y < float32(world.HouseDoorDest.Y+world.HouseDoorDest.Height) &&
-		player.PlayerHitBox.Y+player.PlayerHitBox.Height > float32(world.HouseDoorDest.Y) {
-		enterDungeon()
-	}
-}
+		accumulator += frameTime
 
-func enterDungeon() {
-	if inDungeon {
-		return
-	}
-	inDungeon = true
-	savedWorldPos = rl.NewVector2(player.PlayerDest.X, player.PlayerDest.Y)
-
-	dungeon.Generate()
-	player.SetExternalColliders(dungeon.GetColliders())
-	mobs.SetExternalColliders(dungeon.GetColliders())
-	spawn := dungeon.GetSpawnPosition()
-	player.SetPosition(spawn.X, spawn.Y)
-	exitCooldownFrames = exitCooldownFramesDefault
-
-	baseMin, baseMax := dungeonSpawnBaseMin, dungeonSpawnBaseMax
-	if dungeonSpawnCount == 0 {
-		dungeonSpawnCount = baseMin + int(rl.GetRandomValue(0, int32(baseMax-baseMin)))
-	} else {
-		inc := int(rl.GetRandomValue(2, 5))
-		dungeonSpawnCount += inc
-		if dungeonSpawnCount > baseMax+dungeonSpawnBaseMax {
-			dungeonSpawnCount = baseMax + dungeonSpawnBaseMax
+		input()
+		ticks := 0
+		for accumulator >= game.TickDelta {
+			update()
+			accumulator -= game.TickDelta
+			ticks++
 		}
-	}
-
-	positions := dungeon.GetRandomFloorPositions(dungeonSpawnCount)
-	mobs.ResetMobs()
-	mobs.SpawnMobsAtPositions(positions, "random")
-
-	exitSoundPlayed = false
-	mobsClearFrames = 0
-
-	playTrack("dungeon")
-}
-
-func exitDungeon() {
-	if !inDungeon {
-		return
-	}
-	inDungeon = false
-	player.ClearExternalColliders()
-	mobs.ClearExternalColliders()
-	player.SetPosition(savedWorldPos.X, savedWorldPos.Y)
-	mobs.ResetMobs()
-	playTrack("world")
-}
-
-func nextDungeon() {
-	dungeon.Generate()
-	player.SetExternalColliders(dungeon.GetColliders())
-	mobs.SetExternalColliders(dungeon.GetColliders())
-	spawn := dungeon.GetSpawnPosition()
-	player.SetPosition(spawn.X, spawn.Y)
-	exitCooldownFrames = exitCooldownFramesDefault
-
-	baseMin, baseMax := dungeonSpawnBaseMin, dungeonSpawnBaseMax
-	if dungeonSpawnCount == 0 {
-		dungeonSpawnCount = baseMin + int(rl.GetRandomValue(0, int32(baseMax-baseMin)))
-	} else {
-		inc := int(rl.GetRandomValue(2, 5))
-		dungeonSpawnCount += inc
-		if dungeonSpawnCount > baseMax+dungeonSpawnBaseMax {
-			dungeonSpawnCount = baseMax + dungeonSpawnBaseMax
+		if recorder != nil {
+			recorder.EndFrame(ticks)
 		}
+		render(accumulator / game.TickDelta)
 	}
 
-	positions := dungeon.GetRandomFloorPositions(dungeonSpawnCount)
-	mobs.ResetMobs()
-	mobs.SpawnMobsAtPositions(positions, "random")
-
-	exitSoundPlayed = false
-	mobsClearFrames = 0
-
-	playTrack("dungeon")
-}
-
-func enterBoss() {
-	if inBoss {
-		return
-	}
-	inDungeon = false
-	inBoss = true
-
-	mobs.ResetMobs()
-
-	player.SetExternalColliders(boss.GetColliders())
-	mobs.SetExternalColliders(boss.GetColliders())
-
-	player.SetPosition(548, 285)
-
-	mobs.SpawnBossAtPosition(rl.NewVector2(548, 200))
-
-	playTrack("boss")
-}
-
-func exitBoss() {
-	if !inBoss {
-		return
-	}
-	inBoss = false
-
-	player.SetPosition(495, 344)
-	player.ClearExternalColliders()
-	mobs.ClearExternalColliders()
-	playTrack("world")
+	quit()
 }
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"math"
+
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/boss"
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/game"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	gridSize     = 16
+	attackReach  = 30
+	arriveMargin = 2
+)
+
+// bot plays the game through the headless backend's keyboard and mouse, the
+// same way a person would: walk to the next mob or the exit, click when
+// something is in reach.
+type bot struct {
+	h *backend.Headless
+
+	scene   string
+	level   int
+	blocked [][]bool
+	gridW   int
+	gridH   int
+	// colliders is how many colliders the grid was built from; doors
+	// opening change it.
+	colliders int
+
+	// winChosen is set once Endless is selected on the win screen.
+	winChosen bool
+}
+
+func newBot(h *backend.Headless) *bot {
+	return &bot{h: h}
+}
+
+func (b *bot) tick() {
+	b.h.ReleaseAll()
+
+	scene := game.CurrentScene()
+	if scene != b.scene || game.DungeonsCleared() != b.level {
+		b.scene = scene
+		b.level = game.DungeonsCleared()
+		b.winChosen = false
+		b.buildGrid()
+	} else if scene == "dungeon" && len(dungeon.GetColliders()) != b.colliders {
+		b.buildGrid()
+	}
+
+	switch scene {
+	case "menu", "difficulty", "gameover":
+		b.h.PressKey(rl.KeyEnter)
+		return
+	case "win":
+		// Only reached with -stop-on-win=false: go on in endless mode
+		if !b.winChosen {
+			b.h.PressKey(rl.KeyDown)
+			b.winChosen = true
+		} else {
+			b.h.PressKey(rl.KeyEnter)
+		}
+		return
+	case "":
+		return
+	}
+
+	if player.IsPlayerDead() {
+		return
+	}
+
+	pos := playerCenter()
+	target, ok := b.target(pos)
+	if !ok {
+		return
+	}
+
+	if i := mobs.GetClosestMobIndex(pos); i != -1 {
+		if rl.Vector2Distance(pos, mobs.GetMobHitboxCenterByIndex(i)) <= attackReach {
+			b.h.Click(rl.MouseLeftButton, rl.NewVector2(0, 0))
+		}
+	}
+
+	b.walkTowards(pos, b.nextWaypoint(pos, target))
+}
+
+// target picks where to go: the closest mob, then the exit (dungeon) or the
+// house door (town). In the dungeon keys, chests, levers and doors it can
+// open come first, and what is locked away is left for later.
+func (b *bot) target(pos rl.Vector2) (rl.Vector2, bool) {
+	switch b.scene {
+	case "town":
+		d := world.HouseDoorDest
+		return rl.NewVector2(d.X+d.Width/2, d.Y+d.Height/2), true
+	case "dungeon":
+		dist := b.distances(pos)
+		if g, ok := b.closest(dist, dungeon.LockGoals()); ok {
+			return g, true
+		}
+		var alive []rl.Vector2
+		for i := 0; i < mobs.MobCount(); i++ {
+			if mobs.IsMobAliveByIndex(i) {
+				alive = append(alive, mobs.GetMobHitboxCenterByIndex(i))
+			}
+		}
+		if m, ok := b.closest(dist, alive); ok {
+			return m, true
+		}
+		if i := mobs.GetClosestMobIndex(pos); i != -1 {
+			return mobs.GetMobHitboxCenterByIndex(i), true
+		}
+		if dungeon.IsExitVisible() {
+			e := dungeon.GetExitRect()
+			return rl.NewVector2(e.X+e.Width/2, e.Y+e.Height/2), true
+		}
+	case "boss":
+		if i := mobs.GetClosestMobIndex(pos); i != -1 {
+			return mobs.GetMobHitboxCenterByIndex(i), true
+		}
+	}
+	return rl.Vector2{}, false
+}
+
+func (b *bot) walkTowards(pos, to rl.Vector2) {
+	dx := to.X - pos.X
+	dy := to.Y - pos.Y
+	adx := math.Abs(float64(dx))
+	ady := math.Abs(float64(dy))
+	// One axis at a time, lining up on the shorter one first so the hitbox
+	// doesn't clip the wall next to a corridor opening.
+	horizontal := adx > arriveMargin && (ady <= arriveMargin || adx <= ady)
+	if horizontal {
+		if dx > 0 {
+			b.h.SetKeyDown(rl.KeyD, true)
+		} else {
+			b.h.SetKeyDown(rl.KeyA, true)
+		}
+	} else if ady > arriveMargin {
+		if dy > 0 {
+			b.h.SetKeyDown(rl.KeyS, true)
+		} else {
+			b.h.SetKeyDown(rl.KeyW, true)
+		}
+	}
+}
+
+// nextWaypoint returns the centre of the next grid cell on a shortest path
+// to target, or target itself when it is in the same cell or unreachable.
+func (b *bot) nextWaypoint(pos, target rl.Vector2) rl.Vector2 {
+	if b.blocked == nil {
+		return target
+	}
+	sx, sy := int(pos.X)/gridSize, int(pos.Y)/gridSize
+	tx, ty := int(target.X)/gridSize, int(target.Y)/gridSize
+	if !b.inGrid(sx, sy) || !b.inGrid(tx, ty) || (sx == tx && sy == ty) {
+		return target
+	}
+
+	// BFS from the target so every cell knows its next step towards it
+	prev := make([]int, b.gridW*b.gridH)
+	for i := range prev {
+		prev[i] = -1
+	}
+	queue := []int{ty*b.gridW + tx}
+	prev[ty*b.gridW+tx] = ty*b.gridW + tx
+	dirs := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		cx, cy := c%b.gridW, c/b.gridW
+		if cx == sx && cy == sy {
+			break
+		}
+		for _, d := range dirs {
+			nx, ny := cx+d[0], cy+d[1]
+			if !b.inGrid(nx, ny) || prev[ny*b.gridW+nx] != -1 {
+				continue
+			}
+			if b.blocked[ny][nx] && !(nx == sx && ny == sy) {
+				continue
+			}
+			prev[ny*b.gridW+nx] = c
+			queue = append(queue, ny*b.gridW+nx)
+		}
+	}
+
+	next := prev[sy*b.gridW+sx]
+	if next == -1 {
+		return target
+	}
+	nx, ny := next%b.gridW, next/b.gridW
+	if nx == tx && ny == ty {
+		return target
+	}
+	return rl.NewVector2(float32(nx*gridSize+gridSize/2), float32(ny*gridSize+gridSize/2))
+}
+
+// distances is the walk in grid cells from pos to every cell, -1 where it
+// can't get to.
+func (b *bot) distances(pos rl.Vector2) []int {
+	if b.blocked == nil {
+		return nil
+	}
+	dist := make([]int, b.gridW*b.gridH)
+	for i := range dist {
+		dist[i] = -1
+	}
+	sx, sy := int(pos.X)/gridSize, int(pos.Y)/gridSize
+	if !b.inGrid(sx, sy) {
+		return dist
+	}
+	dist[sy*b.gridW+sx] = 0
+	queue := []int{sy*b.gridW + sx}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := c%b.gridW+d[0], c/b.gridW+d[1]
+			if !b.inGrid(nx, ny) || b.blocked[ny][nx] || dist[ny*b.gridW+nx] != -1 {
+				continue
+			}
+			dist[ny*b.gridW+nx] = dist[c] + 1
+			queue = append(queue, ny*b.gridW+nx)
+		}
+	}
+	return dist
+}
+
+// closest is the point with the shortest walk, counting a blocked cell
+// (a door) as reachable from its neighbours.
+func (b *bot) closest(dist []int, points []rl.Vector2) (rl.Vector2, bool) {
+	if dist == nil {
+		return rl.Vector2{}, false
+	}
+	best, bestDist := rl.Vector2{}, -1
+	for _, p := range points {
+		x, y := int(p.X)/gridSize, int(p.Y)/gridSize
+		if !b.inGrid(x, y) {
+			continue
+		}
+		d := dist[y*b.gridW+x]
+		if b.blocked[y][x] {
+			for _, n := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				if nx, ny := x+n[0], y+n[1]; b.inGrid(nx, ny) && dist[ny*b.gridW+nx] >= 0 && (d < 0 || dist[ny*b.gridW+nx]+1 < d) {
+					d = dist[ny*b.gridW+nx] + 1
+				}
+			}
+		}
+		if d >= 0 && (bestDist < 0 || d < bestDist) {
+			best, bestDist = p, d
+		}
+	}
+	return best, bestDist >= 0
+}
+
+func (b *bot) inGrid(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.gridW && y < b.gridH
+}
+
+// buildGrid rasterises the active scene's colliders into walkable cells.
+func (b *bot) buildGrid() {
+	var rects []rl.Rectangle
+	switch b.scene {
+	case "dungeon":
+		rects = dungeon.GetColliders()
+	case "boss":
+		rects = boss.GetColliders()
+	case "town":
+		ts := float32(world.WorldMap.TileSize)
+		for _, layer := range [][]world.Tile{world.Out, world.Fence, world.Buildings, world.Trees, world.Bushes, world.Markets} {
+			for _, t := range layer {
+				rects = append(rects, rl.NewRectangle(float32(t.X)*ts, float32(t.Y)*ts, ts, ts))
+			}
+		}
+		for _, l := range world.Lamps {
+			rects = append(rects, rl.NewRectangle(float32(l.X), float32(l.Y), 16, 16))
+		}
+	}
+
+	b.blocked = nil
+	b.colliders = len(rects)
+	if len(rects) == 0 {
+		return
+	}
+	maxX, maxY := 0, 0
+	for _, r := range rects {
+		if ex := int(r.X + r.Width); ex > maxX {
+			maxX = ex
+		}
+		if ey := int(r.Y + r.Height); ey > maxY {
+			maxY = ey
+		}
+	}
+	b.gridW = (maxX + gridSize - 1) / gridSize
+	b.gridH = (maxY + gridSize - 1) / gridSize
+	b.blocked = make([][]bool, b.gridH)
+	for y := range b.blocked {
+		b.blocked[y] = make([]bool, b.gridW)
+	}
+	for _, r := range rects {
+		for y := int(r.Y) / gridSize; y <= int(r.Y+r.Height-1)/gridSize; y++ {
+			for x := int(r.X) / gridSize; x <= int(r.X+r.Width-1)/gridSize; x++ {
+				if b.inGrid(x, y) {
+					b.blocked[y][x] = true
+				}
+			}
+		}
+	}
+}
+
+func playerCenter() rl.Vector2 {
+	return rl.NewVector2(player.PlayerHitBox.X+player.PlayerHitBox.Width/2, player.PlayerHitBox.Y+player.PlayerHitBox.Height/2)
+}
@@ -0,0 +1,181 @@
+// Command sim runs the game without a window or audio device. A simple bot
+// plays through town, the dungeon levels and the boss arena so generation,
+// mob AI, combat and level progression can be exercised on a CI box.
+//
+// With -record the bot's input is saved as a replay; -replay plays a replay
+// recorded here or in the game back instead of running the bot, which makes
+// bug reports reproducible and recorded runs usable as regression tests.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	assetpack "spooknloot"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/game"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/replay"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/settings"
+	"spooknloot/pkg/stats"
+)
+
+func main() {
+	ticks := flag.Int("ticks", 60*60*10, "number of simulation ticks to run")
+	report := flag.Int("report", 60*60, "print a status line every n ticks (0 disables)")
+	stopOnWin := flag.Bool("stop-on-win", true, "stop as soon as the boss is defeated")
+	seed := flag.Int64("seed", 0, "run seed (0 = random)")
+	recordPath := flag.String("record", "", "record the bot's input to a replay file")
+	replayPath := flag.String("replay", "", "play back a replay file instead of running the bot")
+	maxDeaths := flag.Int("max-deaths", -1, "fail if the player dies more often than this (-1 = never)")
+	flag.Parse()
+	// Only an explicit -stop-on-win makes defeating the boss a requirement
+	winRequired := false
+	flag.Visit(func(f *flag.Flag) {
+		winRequired = winRequired || (f.Name == "stop-on-win" && *stopOnWin)
+	})
+
+	*recordPath = absPath(*recordPath)
+	*replayPath = absPath(*replayPath)
+	if _, _, err := assetpack.Prepare(); err != nil {
+		fmt.Fprintln(os.Stderr, "sim: extracting assets:", err)
+		os.Exit(1)
+	}
+
+	headless := backend.NewHeadless(1500, 900)
+	backend.Use(headless)
+
+	var recorder *replay.Recorder
+	var playback *replay.Playback
+	if *replayPath != "" {
+		p, err := replay.Load(*replayPath, headless)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "sim: loading replay:", err)
+			os.Exit(1)
+		}
+		playback = p
+		*seed = p.Seed()
+		backend.Use(p)
+	} else if *recordPath != "" {
+		if *seed == 0 {
+			*seed = rng.NewSeed()
+		}
+		recorder = replay.NewRecorder(headless, *seed, settings.Current.Bindings)
+		backend.Use(recorder)
+	}
+
+	game.SetSaving(false)
+	if playback != nil {
+		game.SetBindings(playback.Bindings())
+	}
+	game.SetSeed(*seed)
+	game.Init()
+	fmt.Printf("seed %d\n", game.Seed())
+
+	b := newBot(headless)
+	start := time.Now()
+
+	tick := 0
+	deaths, bossKills, maxLevel := 0, 0, 0
+	wasDead := false
+	// Counted from the event so endless mode's later bosses count too
+	stats.Subscribe(func(e stats.Event) {
+		if e.Kind == stats.BossKilled {
+			bossKills++
+			fmt.Printf("tick %d: boss defeated\n", tick)
+		}
+	})
+	// observe checks the state after a tick and reports whether to stop
+	observe := func(tick int) bool {
+		dead := player.IsPlayerDead()
+		if dead && !wasDead {
+			deaths++
+			fmt.Printf("tick %d: died on level %d\n", tick, game.DungeonsCleared()+1)
+		}
+		wasDead = dead
+
+		if level := game.DungeonsCleared(); level > maxLevel {
+			maxLevel = level
+		}
+
+		scene := game.CurrentScene()
+		if scene == "win" && *stopOnWin {
+			return true
+		}
+
+		if *report > 0 && tick > 0 && tick%*report == 0 {
+			fmt.Printf("tick %d: scene=%s level=%d health=%.1f\n", tick, scene, game.DungeonsCleared()+1, player.GetCurrentHealth())
+		}
+		return false
+	}
+
+	stop := false
+	for tick < *ticks && !stop && !game.QuitRequested() {
+		n := 1
+		if playback != nil {
+			if !playback.NextFrame() {
+				break
+			}
+			n = playback.Ticks()
+		} else {
+			b.tick()
+		}
+
+		game.HandleInput()
+		for i := 0; i < n && !stop; i++ {
+			game.Update(game.TickDelta)
+			stop = observe(tick)
+			tick++
+		}
+		if recorder != nil {
+			recorder.EndFrame(n)
+		}
+		headless.EndFrame()
+	}
+
+	if recorder != nil {
+		if err := recorder.Save(*recordPath); err != nil {
+			fmt.Fprintln(os.Stderr, "sim: saving replay:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("recorded %d frames to %s\n", recorder.Frames(), *recordPath)
+	}
+
+	elapsed := time.Since(start)
+	fmt.Printf("ran %d ticks (%.0f game seconds) in %v\n", tick, float32(tick)*game.TickDelta, elapsed.Round(time.Millisecond))
+	fmt.Printf("deepest level: %d, deaths: %d, boss kills: %d\n", maxLevel+1, deaths, bossKills)
+
+	// A non-zero status lets CI fail the run
+	failed := false
+	if *maxDeaths >= 0 && deaths > *maxDeaths {
+		fmt.Fprintf(os.Stderr, "sim: the player died %d times, at most %d allowed\n", deaths, *maxDeaths)
+		failed = true
+	}
+	if winRequired && bossKills == 0 {
+		fmt.Fprintln(os.Stderr, "sim: the boss was never defeated")
+		failed = true
+	}
+	if playback != nil {
+		if pos, total := playback.Progress(); pos < total && tick < *ticks {
+			fmt.Fprintf(os.Stderr, "sim: the replay stopped early at frame %d of %d\n", pos, total)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -11,7 +11,7 @@ import (
 // Embedded holds all game assets and required data files.
 // Patterns are relative to this file's directory (project root).
 //
-//go:embed assets/** pkg/world/map.json pkg/boss/map.json spooknloot.json
+//go:embed assets/** pkg/world/map.json pkg/boss/map.json pkg/achievements/achievements.json pkg/difficulty/difficulty.json pkg/daily/modifiers.json pkg/dungeon/rooms.json pkg/dungeon/prefabs/*.json spooknloot.json
 var Embedded embed.FS
 
 // Prepare extracts embedded assets into a temporary directory and switches
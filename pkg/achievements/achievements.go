@@ -0,0 +1,181 @@
+// Package achievements unlocks the achievements listed in achievements.json
+// from the game's stats events. Unlocks and progress go through a Backend:
+// a local file by default, a platform adapter (e.g. Steam) if one is set
+// with Use.
+package achievements
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"spooknloot/pkg/stats"
+)
+
+// Achievement is one entry of achievements.json. It unlocks on the first
+// Event that matches every set field, or once Count matching events have
+// happened over all runs.
+type Achievement struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Event is one of the keys of eventKinds.
+	Event   string `json:"event"`
+	MobType string `json:"mobType,omitempty"`
+	Egg     string `json:"egg,omitempty"`
+	// Level is the lowest level a levelEntered event has to reach.
+	Level int `json:"level,omitempty"`
+	Count int `json:"count,omitempty"`
+	// RunMax caps stats of the current run, see runValue.
+	RunMax map[string]float32 `json:"runMax,omitempty"`
+}
+
+var eventKinds = map[string]stats.Kind{
+	"mobKilled":    stats.MobKilled,
+	"potionDrunk":  stats.PotionDrunk,
+	"levelEntered": stats.LevelEntered,
+	"bossKilled":   stats.BossKilled,
+	"playerDied":   stats.PlayerDied,
+	"easterEgg":    stats.EasterEggFound,
+}
+
+var (
+	list     []Achievement
+	current  Backend = NewFileBackend()
+	progress Progress
+	// unlocked since the last PopUnlocked, oldest first
+	pending    []Achievement
+	subscribed bool
+)
+
+// Use replaces the backend. Call before Init.
+func Use(b Backend) {
+	current = b
+}
+
+// Init reads the achievement list from path, loads the progress from the
+// backend and starts listening to stats events.
+func Init(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var l []Achievement
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	for _, a := range l {
+		if _, ok := eventKinds[a.Event]; !ok {
+			return fmt.Errorf("achievement %q: unknown event %q", a.ID, a.Event)
+		}
+	}
+	list = l
+
+	p, err := current.Load()
+	progress = p
+	if progress.Unlocked == nil {
+		progress.Unlocked = map[string]string{}
+	}
+	if progress.Counts == nil {
+		progress.Counts = map[string]int{}
+	}
+
+	if !subscribed {
+		stats.Subscribe(handle)
+		subscribed = true
+	}
+	return err
+}
+
+func handle(e stats.Event) {
+	for _, a := range list {
+		if _, done := progress.Unlocked[a.ID]; done || !a.matches(e) {
+			continue
+		}
+		if a.Count > 1 {
+			progress.Counts[a.ID]++
+			n := progress.Counts[a.ID]
+			if err := current.SetProgress(a.ID, n, a.Count); err != nil {
+				fmt.Fprintln(os.Stderr, "saving achievement progress:", err)
+			}
+			if n < a.Count {
+				continue
+			}
+		}
+		unlock(a)
+	}
+}
+
+func (a Achievement) matches(e stats.Event) bool {
+	if eventKinds[a.Event] != e.Kind {
+		return false
+	}
+	if a.MobType != "" && a.MobType != e.MobType {
+		return false
+	}
+	if a.Egg != "" && a.Egg != e.Name {
+		return false
+	}
+	if a.Level > 0 && e.Level < a.Level {
+		return false
+	}
+	run := stats.Current()
+	for name, max := range a.RunMax {
+		if runValue(run, name) > max {
+			return false
+		}
+	}
+	return true
+}
+
+// runValue looks up a stat of the current run by its name in RunMax.
+func runValue(r stats.Run, name string) float32 {
+	switch name {
+	case "potions":
+		return float32(r.Potions)
+	case "damageTaken":
+		return r.DamageTaken
+	case "kills":
+		return float32(r.TotalKills())
+	case "time":
+		return r.Time
+	}
+	return 0
+}
+
+func unlock(a Achievement) {
+	progress.Unlocked[a.ID] = today()
+	if err := current.Unlock(a.ID); err != nil {
+		fmt.Fprintln(os.Stderr, "saving achievement:", err)
+	}
+	pending = append(pending, a)
+}
+
+// PopUnlocked returns the oldest achievement unlocked since the last call,
+// for the HUD to announce.
+func PopUnlocked() (Achievement, bool) {
+	if len(pending) == 0 {
+		return Achievement{}, false
+	}
+	a := pending[0]
+	pending = pending[1:]
+	return a, true
+}
+
+// All returns every achievement in the order of achievements.json.
+func All() []Achievement {
+	return list
+}
+
+func IsUnlocked(id string) bool {
+	_, ok := progress.Unlocked[id]
+	return ok
+}
+
+// Flush writes pending progress, call before quitting.
+func Flush() {
+	if err := current.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "saving achievements:", err)
+	}
+}
@@ -0,0 +1,113 @@
+package achievements
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Backend stores what has been unlocked. Unlock is called once per
+// achievement; SetProgress for every step of counted achievements, so
+// backends should batch those until Flush.
+type Backend interface {
+	Load() (Progress, error)
+	Unlock(id string) error
+	SetProgress(id string, count, goal int) error
+	Flush() error
+}
+
+// Progress is the state a backend keeps.
+type Progress struct {
+	// Unlocked maps achievement IDs to the day they were unlocked.
+	Unlocked map[string]string `json:"unlocked"`
+	Counts   map[string]int    `json:"counts"`
+}
+
+// FileBackend keeps the progress in achievements.json in the config
+// directory, next to the settings.
+type FileBackend struct {
+	progress Progress
+	dirty    bool
+}
+
+func NewFileBackend() *FileBackend {
+	return &FileBackend{}
+}
+
+func filePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spooknloot", "achievements.json"), nil
+}
+
+func (f *FileBackend) Load() (Progress, error) {
+	f.progress = Progress{Unlocked: map[string]string{}, Counts: map[string]int{}}
+	path, err := filePath()
+	if err != nil {
+		return f.progress, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f.progress, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &f.progress)
+	}
+	return f.progress, err
+}
+
+func (f *FileBackend) Unlock(id string) error {
+	if f.progress.Unlocked == nil {
+		f.progress.Unlocked = map[string]string{}
+	}
+	f.progress.Unlocked[id] = today()
+	f.dirty = true
+	return f.Flush()
+}
+
+func (f *FileBackend) SetProgress(id string, count, goal int) error {
+	if f.progress.Counts == nil {
+		f.progress.Counts = map[string]int{}
+	}
+	f.progress.Counts[id] = count
+	f.dirty = true
+	return nil
+}
+
+func (f *FileBackend) Flush() error {
+	if !f.dirty {
+		return nil
+	}
+	path, err := filePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f.progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	f.dirty = false
+	return nil
+}
+
+// MemoryBackend forgets everything on exit. Used by replays and the
+// headless simulation, which must not unlock anything for the player.
+type MemoryBackend struct{}
+
+func (MemoryBackend) Load() (Progress, error)                      { return Progress{}, nil }
+func (MemoryBackend) Unlock(id string) error                       { return nil }
+func (MemoryBackend) SetProgress(id string, count, goal int) error { return nil }
+func (MemoryBackend) Flush() error                                 { return nil }
+
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
@@ -0,0 +1,152 @@
+// Package audio owns every sound of the game. Music tracks and sound
+// effects are listed once in library.go, loaded and unloaded together and
+// played by name. Volumes go through a master, a music and an effects bus.
+package audio
+
+import (
+	"os"
+
+	"spooknloot/pkg/backend"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Bus is a volume control shared by a group of sounds.
+type Bus int
+
+const (
+	Master Bus = iota
+	Music
+	SFX
+)
+
+// FadeTime is how long a crossfade between two tracks takes, in seconds.
+var FadeTime float32 = 1.0
+
+type track struct {
+	path   string
+	volume float32
+	music  rl.Music
+	loaded bool
+
+	// fade is the track's current share of its volume (0..1), step how
+	// much it changes per second while a crossfade runs.
+	fade    float32
+	step    float32
+	playing bool
+}
+
+type sound struct {
+	path   string
+	volume float32
+	sound  rl.Sound
+	loaded bool
+}
+
+var (
+	tracks = map[string]*track{}
+	sounds = map[string]*sound{}
+
+	buses = [3]float32{1, 1, 1}
+
+	current string
+	paused  bool
+)
+
+func init() {
+	for _, t := range library.tracks {
+		RegisterTrack(t.name, t.path, t.volume)
+	}
+	for _, s := range library.sounds {
+		RegisterSound(s.name, s.path, s.volume)
+	}
+}
+
+// RegisterTrack adds a music track. volume is the track's own mix level
+// before the buses are applied. Tracks registered after Init are loaded on
+// the next Init.
+func RegisterTrack(name, path string, volume float32) {
+	tracks[name] = &track{path: path, volume: volume}
+}
+
+// RegisterSound adds a sound effect, see RegisterTrack.
+func RegisterSound(name, path string, volume float32) {
+	sounds[name] = &sound{path: path, volume: volume}
+}
+
+// Init loads every registered track and sound whose file exists. The audio
+// device must already be open.
+func Init() {
+	for _, t := range tracks {
+		if t.loaded {
+			continue
+		}
+		if _, err := os.Stat(t.path); err == nil {
+			t.music = backend.Current.LoadMusic(t.path)
+			t.loaded = true
+		}
+	}
+	for _, s := range sounds {
+		if s.loaded {
+			continue
+		}
+		if _, err := os.Stat(s.path); err == nil {
+			s.sound = backend.Current.LoadSound(s.path)
+			s.loaded = true
+		}
+	}
+	applyVolumes()
+}
+
+// Unload stops and frees everything Init loaded.
+func Unload() {
+	for _, t := range tracks {
+		if !t.loaded {
+			continue
+		}
+		backend.Current.StopMusic(t.music)
+		backend.Current.UnloadMusic(t.music)
+		*t = track{path: t.path, volume: t.volume}
+	}
+	for _, s := range sounds {
+		if !s.loaded {
+			continue
+		}
+		backend.Current.UnloadSound(s.sound)
+		s.loaded = false
+	}
+	current = ""
+}
+
+// SetBusVolume sets a bus to v (0..1).
+func SetBusVolume(b Bus, v float32) {
+	if v < 0 {
+		v = 0
+	}
+	if v > 1 {
+		v = 1
+	}
+	buses[b] = v
+	applyVolumes()
+}
+
+func BusVolume(b Bus) float32 {
+	return buses[b]
+}
+
+func applyVolumes() {
+	for _, t := range tracks {
+		if t.loaded {
+			backend.Current.SetMusicVolume(t.music, t.mixVolume())
+		}
+	}
+	for _, s := range sounds {
+		if s.loaded {
+			backend.Current.SetSoundVolume(s.sound, s.volume*buses[SFX]*buses[Master])
+		}
+	}
+}
+
+func (t *track) mixVolume() float32 {
+	return t.volume * t.fade * buses[Music] * buses[Master]
+}
@@ -0,0 +1,27 @@
+package audio
+
+type entry struct {
+	name   string
+	path   string
+	volume float32
+}
+
+// library lists every sound the game ships with. A new track or effect
+// only needs a line here.
+var library = struct {
+	tracks []entry
+	sounds []entry
+}{
+	tracks: []entry{
+		{"world", "assets/audio/world.mp3", 0.2},
+		{"dungeon", "assets/audio/dungeon.mp3", 0.6},
+		{"boss", "assets/audio/boss.mp3", 0.6},
+	},
+	sounds: []entry{
+		{"attack", "assets/audio/attack.mp3", 0.6},
+		{"damage", "assets/audio/damage.mp3", 0.6},
+		{"walking", "assets/audio/walking.mp3", 0.35},
+		{"door", "assets/audio/open.mp3", 0.7},
+		{"drink", "assets/audio/drink.mp3", 0.7},
+	},
+}
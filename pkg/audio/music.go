@@ -0,0 +1,115 @@
+package audio
+
+import "spooknloot/pkg/backend"
+
+// PlayTrack crossfades from the current track to name. Playing the track
+// that is already on does nothing; an unknown or missing track fades the
+// music out.
+func PlayTrack(name string) {
+	if name == current {
+		return
+	}
+	if t := tracks[current]; t != nil && t.playing {
+		t.step = -1 / FadeTime
+	}
+	current = name
+
+	t := tracks[name]
+	if t == nil || !t.loaded {
+		return
+	}
+	if !t.playing {
+		t.fade = 0
+		backend.Current.PlayMusic(t.music)
+		t.playing = true
+		if paused {
+			backend.Current.PauseMusic(t.music)
+		}
+	}
+	t.step = 1 / FadeTime
+	// Only the new track is left once the music is paused
+	if paused {
+		finishFades()
+	}
+}
+
+// CurrentTrack is the track last asked for with PlayTrack.
+func CurrentTrack() string {
+	return current
+}
+
+// Update streams the playing tracks and advances crossfades by dt seconds.
+func Update(dt float32) {
+	for _, t := range tracks {
+		if !t.playing {
+			continue
+		}
+		backend.Current.UpdateMusic(t.music)
+		if paused || t.step == 0 {
+			continue
+		}
+		t.fade += t.step * dt
+		if t.fade >= 1 {
+			t.fade = 1
+			t.step = 0
+		}
+		if t.fade <= 0 {
+			t.stop()
+			continue
+		}
+		backend.Current.SetMusicVolume(t.music, t.mixVolume())
+	}
+}
+
+// finishFades jumps to the end of every running crossfade.
+func finishFades() {
+	for _, t := range tracks {
+		if !t.playing || t.step == 0 {
+			continue
+		}
+		if t.step < 0 {
+			t.stop()
+			continue
+		}
+		t.fade = 1
+		t.step = 0
+		backend.Current.SetMusicVolume(t.music, t.mixVolume())
+	}
+}
+
+func (t *track) stop() {
+	backend.Current.StopMusic(t.music)
+	t.playing = false
+	t.fade = 0
+	t.step = 0
+}
+
+// Pause halts the music until Resume. Sound effects keep playing.
+func Pause() {
+	if paused {
+		return
+	}
+	paused = true
+	finishFades()
+	for _, t := range tracks {
+		if t.playing {
+			backend.Current.PauseMusic(t.music)
+		}
+	}
+}
+
+func Resume() {
+	if !paused {
+		return
+	}
+	paused = false
+	for _, t := range tracks {
+		if t.playing {
+			backend.Current.ResumeMusic(t.music)
+		}
+	}
+}
+
+func Paused() bool {
+	return paused
+}
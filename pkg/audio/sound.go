@@ -0,0 +1,32 @@
+package audio
+
+import "spooknloot/pkg/backend"
+
+// PlaySound starts a sound effect unless it is already playing.
+func PlaySound(name string) {
+	if s := sounds[name]; s != nil && s.loaded && !backend.Current.IsSoundPlaying(s.sound) {
+		backend.Current.PlaySound(s.sound)
+	}
+}
+
+// RestartSound plays a sound effect from the beginning, cutting off a
+// previous play.
+func RestartSound(name string) {
+	if s := sounds[name]; s != nil && s.loaded {
+		if backend.Current.IsSoundPlaying(s.sound) {
+			backend.Current.StopSound(s.sound)
+		}
+		backend.Current.PlaySound(s.sound)
+	}
+}
+
+func StopSound(name string) {
+	if s := sounds[name]; s != nil && s.loaded && backend.Current.IsSoundPlaying(s.sound) {
+		backend.Current.StopSound(s.sound)
+	}
+}
+
+func IsSoundPlaying(name string) bool {
+	s := sounds[name]
+	return s != nil && s.loaded && backend.Current.IsSoundPlaying(s.sound)
+}
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Backend is everything the game logic needs from the platform besides
+// drawing: resource loading, audio playback, input and the window size.
+// Drawing still goes straight to raylib because it only happens when a
+// window exists.
+type Backend interface {
+	LoadTexture(path string) rl.Texture2D
+	UnloadTexture(t rl.Texture2D)
+	SetTextureFilter(t rl.Texture2D, filter rl.TextureFilterMode)
+	LoadFont(path string, size int32) rl.Font
+	UnloadFont(f rl.Font)
+
+	LoadSound(path string) rl.Sound
+	UnloadSound(s rl.Sound)
+	SetSoundVolume(s rl.Sound, volume float32)
+	PlaySound(s rl.Sound)
+	StopSound(s rl.Sound)
+	IsSoundPlaying(s rl.Sound) bool
+
+	LoadMusic(path string) rl.Music
+	UnloadMusic(m rl.Music)
+	SetMusicVolume(m rl.Music, volume float32)
+	PlayMusic(m rl.Music)
+	StopMusic(m rl.Music)
+	PauseMusic(m rl.Music)
+	ResumeMusic(m rl.Music)
+	UpdateMusic(m rl.Music)
+
+	IsKeyDown(key int32) bool
+	IsKeyPressed(key int32) bool
+	IsMouseButtonDown(button rl.MouseButton) bool
+	IsMouseButtonPressed(button rl.MouseButton) bool
+	MousePosition() rl.Vector2
+	IsGamepadAvailable(pad int32) bool
+	IsGamepadButtonDown(pad, button int32) bool
+	IsGamepadButtonPressed(pad, button int32) bool
+	GamepadAxis(pad, axis int32) float32
+
+	ScreenWidth() int
+	ScreenHeight() int
+	ToggleBorderlessWindowed()
+	SetWindowSize(width, height int)
+}
+
+// Current is the backend used by all game packages. It defaults to the
+// raylib window backend; headless tools swap it out with Use before any
+// Init function runs.
+var Current Backend = Raylib{}
+
+func Use(b Backend) {
+	Current = b
+}
@@ -0,0 +1,151 @@
+package backend
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Headless runs the game without a window, GPU or audio device. Resources
+// load as empty handles, sounds never play and input comes from whoever
+// drives the simulation through SetKeyDown, PressKey and Click.
+type Headless struct {
+	width, height int
+
+	keysDown     map[int32]bool
+	keysPressed  map[int32]bool
+	mouseDown    map[rl.MouseButton]bool
+	mousePressed map[rl.MouseButton]bool
+	mouse        rl.Vector2
+
+	// A single gamepad, connected as soon as any of its inputs is used.
+	padConnected bool
+	padDown      map[int32]bool
+	padPressed   map[int32]bool
+	padAxes      map[int32]float32
+}
+
+func NewHeadless(width, height int) *Headless {
+	return &Headless{
+		width:        width,
+		height:       height,
+		keysDown:     map[int32]bool{},
+		keysPressed:  map[int32]bool{},
+		mouseDown:    map[rl.MouseButton]bool{},
+		mousePressed: map[rl.MouseButton]bool{},
+		padDown:      map[int32]bool{},
+		padPressed:   map[int32]bool{},
+		padAxes:      map[int32]float32{},
+	}
+}
+
+// SetKeyDown holds or releases a key. Going from up to down also counts as
+// a press for the current frame.
+func (h *Headless) SetKeyDown(key int32, down bool) {
+	if down && !h.keysDown[key] {
+		h.keysPressed[key] = true
+	}
+	h.keysDown[key] = down
+}
+
+// PressKey taps a key for the current frame only.
+func (h *Headless) PressKey(key int32) {
+	h.keysPressed[key] = true
+}
+
+// SetMouseButtonDown holds or releases a mouse button, like SetKeyDown.
+func (h *Headless) SetMouseButtonDown(button rl.MouseButton, down bool) {
+	if down && !h.mouseDown[button] {
+		h.mousePressed[button] = true
+	}
+	h.mouseDown[button] = down
+}
+
+// SetGamepadButtonDown holds or releases a button of gamepad 0.
+func (h *Headless) SetGamepadButtonDown(button int32, down bool) {
+	h.padConnected = true
+	if down && !h.padDown[button] {
+		h.padPressed[button] = true
+	}
+	h.padDown[button] = down
+}
+
+// SetGamepadAxis moves an axis of gamepad 0, -1..1.
+func (h *Headless) SetGamepadAxis(axis int32, value float32) {
+	h.padConnected = true
+	h.padAxes[axis] = value
+}
+
+// Click presses a mouse button at pos for the current frame.
+func (h *Headless) Click(button rl.MouseButton, pos rl.Vector2) {
+	h.mouse = pos
+	h.mousePressed[button] = true
+}
+
+// ReleaseAll lets go of every held key and button and centres the sticks.
+func (h *Headless) ReleaseAll() {
+	for k := range h.keysDown {
+		delete(h.keysDown, k)
+	}
+	for b := range h.mouseDown {
+		delete(h.mouseDown, b)
+	}
+	for b := range h.padDown {
+		delete(h.padDown, b)
+	}
+	for a := range h.padAxes {
+		delete(h.padAxes, a)
+	}
+}
+
+// EndFrame forgets this frame's presses; call it after every tick.
+func (h *Headless) EndFrame() {
+	for k := range h.keysPressed {
+		delete(h.keysPressed, k)
+	}
+	for b := range h.mousePressed {
+		delete(h.mousePressed, b)
+	}
+	for b := range h.padPressed {
+		delete(h.padPressed, b)
+	}
+}
+
+func (h *Headless) LoadTexture(path string) rl.Texture2D                    { return rl.Texture2D{} }
+func (h *Headless) UnloadTexture(t rl.Texture2D)                            {}
+func (h *Headless) SetTextureFilter(t rl.Texture2D, f rl.TextureFilterMode) {}
+func (h *Headless) LoadFont(path string, size int32) rl.Font                { return rl.Font{} }
+func (h *Headless) UnloadFont(f rl.Font)                                    {}
+func (h *Headless) LoadSound(path string) rl.Sound                          { return rl.Sound{} }
+func (h *Headless) UnloadSound(s rl.Sound)                                  {}
+func (h *Headless) SetSoundVolume(s rl.Sound, volume float32)               {}
+func (h *Headless) PlaySound(s rl.Sound)                                    {}
+func (h *Headless) StopSound(s rl.Sound)                                    {}
+func (h *Headless) IsSoundPlaying(s rl.Sound) bool                          { return false }
+func (h *Headless) LoadMusic(path string) rl.Music                          { return rl.Music{} }
+func (h *Headless) UnloadMusic(m rl.Music)                                  {}
+func (h *Headless) SetMusicVolume(m rl.Music, volume float32)               {}
+func (h *Headless) PlayMusic(m rl.Music)                                    {}
+func (h *Headless) StopMusic(m rl.Music)                                    {}
+func (h *Headless) PauseMusic(m rl.Music)                                   {}
+func (h *Headless) ResumeMusic(m rl.Music)                                  {}
+func (h *Headless) UpdateMusic(m rl.Music)                                  {}
+func (h *Headless) IsKeyDown(key int32) bool                                { return h.keysDown[key] }
+func (h *Headless) IsKeyPressed(key int32) bool                             { return h.keysPressed[key] }
+func (h *Headless) IsMouseButtonDown(b rl.MouseButton) bool                 { return h.mouseDown[b] }
+func (h *Headless) IsMouseButtonPressed(b rl.MouseButton) bool              { return h.mousePressed[b] }
+func (h *Headless) MousePosition() rl.Vector2                               { return h.mouse }
+func (h *Headless) IsGamepadAvailable(pad int32) bool                       { return pad == 0 && h.padConnected }
+func (h *Headless) IsGamepadButtonDown(pad, b int32) bool                   { return pad == 0 && h.padDown[b] }
+func (h *Headless) IsGamepadButtonPressed(pad, b int32) bool                { return pad == 0 && h.padPressed[b] }
+func (h *Headless) GamepadAxis(pad, axis int32) float32 {
+	if pad != 0 {
+		return 0
+	}
+	return h.padAxes[axis]
+}
+func (h *Headless) ScreenWidth() int          { return h.width }
+func (h *Headless) ScreenHeight() int         { return h.height }
+func (h *Headless) ToggleBorderlessWindowed() {}
+
+func (h *Headless) SetWindowSize(width, height int) {
+	h.width, h.height = width, height
+}
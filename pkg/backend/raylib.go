@@ -0,0 +1,71 @@
+package backend
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Raylib forwards everything to a real raylib window and audio device.
+type Raylib struct{}
+
+// fontCodepoints are the characters loaded from font files: ASCII plus
+// Latin-1, which covers the umlauts of the German translation.
+var fontCodepoints = func() []rune {
+	var r []rune
+	for c := rune(32); c < 256; c++ {
+		if c < 127 || c >= 160 {
+			r = append(r, c)
+		}
+	}
+	return r
+}()
+
+func (Raylib) LoadTexture(path string) rl.Texture2D { return rl.LoadTexture(path) }
+func (Raylib) UnloadTexture(t rl.Texture2D)         { rl.UnloadTexture(t) }
+func (Raylib) SetTextureFilter(t rl.Texture2D, filter rl.TextureFilterMode) {
+	rl.SetTextureFilter(t, filter)
+}
+func (Raylib) LoadFont(path string, size int32) rl.Font {
+	return rl.LoadFontEx(path, size, fontCodepoints)
+}
+func (Raylib) UnloadFont(f rl.Font) { rl.UnloadFont(f) }
+
+func (Raylib) LoadSound(path string) rl.Sound             { return rl.LoadSound(path) }
+func (Raylib) UnloadSound(s rl.Sound)                     { rl.UnloadSound(s) }
+func (Raylib) SetSoundVolume(s rl.Sound, volume float32)  { rl.SetSoundVolume(s, volume) }
+func (Raylib) PlaySound(s rl.Sound)                       { rl.PlaySound(s) }
+func (Raylib) StopSound(s rl.Sound)                       { rl.StopSound(s) }
+func (Raylib) IsSoundPlaying(s rl.Sound) bool             { return rl.IsSoundPlaying(s) }
+func (Raylib) LoadMusic(path string) rl.Music             { return rl.LoadMusicStream(path) }
+func (Raylib) UnloadMusic(m rl.Music)                     { rl.UnloadMusicStream(m) }
+func (Raylib) SetMusicVolume(m rl.Music, volume float32)  { rl.SetMusicVolume(m, volume) }
+func (Raylib) PlayMusic(m rl.Music)                       { rl.PlayMusicStream(m) }
+func (Raylib) StopMusic(m rl.Music)                       { rl.StopMusicStream(m) }
+func (Raylib) PauseMusic(m rl.Music)                      { rl.PauseMusicStream(m) }
+func (Raylib) ResumeMusic(m rl.Music)                     { rl.ResumeMusicStream(m) }
+func (Raylib) UpdateMusic(m rl.Music)                     { rl.UpdateMusicStream(m) }
+func (Raylib) IsKeyDown(key int32) bool                   { return rl.IsKeyDown(key) }
+func (Raylib) IsKeyPressed(key int32) bool                { return rl.IsKeyPressed(key) }
+func (Raylib) IsMouseButtonDown(b rl.MouseButton) bool    { return rl.IsMouseButtonDown(b) }
+func (Raylib) IsMouseButtonPressed(b rl.MouseButton) bool { return rl.IsMouseButtonPressed(b) }
+func (Raylib) MousePosition() rl.Vector2                  { return rl.GetMousePosition() }
+func (Raylib) IsGamepadAvailable(pad int32) bool          { return rl.IsGamepadAvailable(pad) }
+func (Raylib) IsGamepadButtonDown(pad, button int32) bool { return rl.IsGamepadButtonDown(pad, button) }
+func (Raylib) IsGamepadButtonPressed(pad, button int32) bool {
+	return rl.IsGamepadButtonPressed(pad, button)
+}
+func (Raylib) GamepadAxis(pad, axis int32) float32 { return rl.GetGamepadAxisMovement(pad, axis) }
+func (Raylib) ScreenWidth() int                    { return rl.GetScreenWidth() }
+func (Raylib) ScreenHeight() int                   { return rl.GetScreenHeight() }
+func (Raylib) ToggleBorderlessWindowed()           { rl.ToggleBorderlessWindowed() }
+
+// SetWindowSize resizes the window, but never beyond the monitor.
+func (Raylib) SetWindowSize(width, height int) {
+	monitor := rl.GetCurrentMonitor()
+	if w := rl.GetMonitorWidth(monitor); w > 0 && w < width {
+		width = w
+	}
+	if h := rl.GetMonitorHeight(monitor); h > 0 && h < height {
+		height = h
+	}
+	rl.SetWindowSize(width, height)
+}
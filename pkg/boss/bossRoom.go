@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"spooknloot/pkg/backend"
 	"spooknloot/pkg/world"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -50,16 +51,11 @@ func LoadMap(mapFile string) {
 
 	json.Unmarshal(byteValue, &BossMap)
 
+	assignLayers()
 	buildColliders()
 }
 
-func Init() {
-	SpritesheetMap = rl.LoadTexture("assets/boss/spritesheet.png")
-	tileDest = rl.NewRectangle(0, 0, 16, 16)
-	tileSrc = rl.NewRectangle(0, 0, 16, 16)
-}
-
-func Draw() {
+func assignLayers() {
 	for i := 0; i < len(BossMap.Layers); i++ {
 		if BossMap.Layers[i].Name == "Walls" {
 			Out = BossMap.Layers[i].Tiles
@@ -75,7 +71,15 @@ func Draw() {
 			Decoration = BossMap.Layers[i].Tiles
 		}
 	}
+}
 
+func Init() {
+	SpritesheetMap = backend.Current.LoadTexture("assets/boss/spritesheet.png")
+	tileDest = rl.NewRectangle(0, 0, 16, 16)
+	tileSrc = rl.NewRectangle(0, 0, 16, 16)
+}
+
+func Draw() {
 	rl.DrawTexturePro(tex, tileSrc, tileDest, rl.NewVector2(0, 0), 0, rl.White)
 
 	renderLayer(FloorTiles)
@@ -108,7 +112,7 @@ func renderLayer(Layer []world.Tile) {
 }
 
 func Unload() {
-	rl.UnloadTexture(SpritesheetMap)
+	backend.Current.UnloadTexture(SpritesheetMap)
 }
 
 func GetColliders() []rl.Rectangle {
@@ -0,0 +1,123 @@
+// Package daily derives the daily challenge from the date: the run seed and
+// a few modifiers from modifiers.json. Everyone playing on the same day
+// gets the same dungeons, the same mobs and the same rules.
+package daily
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"time"
+
+	"spooknloot/pkg/difficulty"
+)
+
+// modifiersPerDay is how many modifiers a daily challenge has.
+const modifiersPerDay = 2
+
+// Modifier changes the rules of a daily run. The factors multiply the
+// values of the normal difficulty preset; 0 leaves a value alone.
+type Modifier struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	PlayerHealth float32 `json:"playerHealth,omitempty"`
+	MobHealth    float32 `json:"mobHealth,omitempty"`
+	MobDamage    float32 `json:"mobDamage,omitempty"`
+	BossHealth   float32 `json:"bossHealth,omitempty"`
+	Spawns       float32 `json:"spawns,omitempty"`
+
+	// MobTypes limits the mobs that spawn, see mobs.SetSpawnTypes.
+	MobTypes []string `json:"mobTypes,omitempty"`
+}
+
+var modifiers []Modifier
+
+// Load reads the modifier pool from path.
+func Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var l []Modifier
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	if len(l) < modifiersPerDay {
+		return fmt.Errorf("%s: need at least %d modifiers", path, modifiersPerDay)
+	}
+	modifiers = l
+	return nil
+}
+
+// Today is the date of today's challenge, YYYY-MM-DD in UTC so players in
+// every time zone get the same challenge.
+func Today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// Seed is the run seed of the challenge on date.
+func Seed(date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("spooknloot daily " + date))
+	return int64(h.Sum64() & 0x7fffffffffffffff)
+}
+
+// Modifiers picks the modifiers of the challenge on date. Two modifiers
+// that both limit the mob types are never picked together.
+func Modifiers(date string) []Modifier {
+	if len(modifiers) < modifiersPerDay {
+		return nil
+	}
+	r := rand.New(rand.NewSource(Seed(date)))
+	var picked []Modifier
+	limited := false
+	for _, i := range r.Perm(len(modifiers)) {
+		m := modifiers[i]
+		if len(m.MobTypes) > 0 {
+			if limited {
+				continue
+			}
+			limited = true
+		}
+		picked = append(picked, m)
+		if len(picked) == modifiersPerDay {
+			break
+		}
+	}
+	return picked
+}
+
+// Apply returns p changed by every modifier in mods.
+func Apply(p difficulty.Preset, mods []Modifier) difficulty.Preset {
+	scale := func(v *float32, f float32) {
+		if f > 0 {
+			*v *= f
+		}
+	}
+	for _, m := range mods {
+		scale(&p.PlayerHealth, m.PlayerHealth)
+		scale(&p.MobHealth, m.MobHealth)
+		scale(&p.MobDamage, m.MobDamage)
+		scale(&p.BossDamage, m.MobDamage)
+		scale(&p.BossHealth, m.BossHealth)
+		if m.Spawns > 0 {
+			p.SpawnMin = max(1, int(float32(p.SpawnMin)*m.Spawns+0.5))
+			p.SpawnMax = max(p.SpawnMin, int(float32(p.SpawnMax)*m.Spawns+0.5))
+		}
+	}
+	return p
+}
+
+// MobTypes is the mob types mods limit spawns to, or nil for all of them.
+func MobTypes(mods []Modifier) []string {
+	for _, m := range mods {
+		if len(m.MobTypes) > 0 {
+			return m.MobTypes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package difficulty holds the difficulty presets listed in
+// difficulty.json. A preset fixes every number that decides how hard a run
+// is; the game hands them to the mobs and player packages when a run starts.
+package difficulty
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Default is the preset used when none has been chosen yet.
+const Default = "normal"
+
+// Preset is one entry of difficulty.json.
+type Preset struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// SpawnMin and SpawnMax bound the number of mobs on the first dungeon
+	// level. Later levels add a few each, up to twice SpawnMax.
+	SpawnMin int `json:"spawnMin"`
+	SpawnMax int `json:"spawnMax"`
+
+	// MobDamage is what one mob hit costs the player in town and dungeon,
+	// BossDamage the same in the boss arena.
+	MobDamage  float32 `json:"mobDamage"`
+	BossDamage float32 `json:"bossDamage"`
+
+	MobHealth    float32 `json:"mobHealth"`
+	BossHealth   float32 `json:"bossHealth"`
+	PlayerHealth float32 `json:"playerHealth"`
+
+	// BossArmor is the share of the player's damage the boss actually takes.
+	BossArmor float32 `json:"bossArmor"`
+}
+
+// normal is used if difficulty.json can't be read, so the game stays
+// playable. It matches the normal entry of the file.
+var normal = Preset{
+	ID:           Default,
+	Name:         "Normal",
+	SpawnMin:     5,
+	SpawnMax:     10,
+	MobDamage:    0.3,
+	BossDamage:   0.6,
+	MobHealth:    5,
+	BossHealth:   100,
+	PlayerHealth: 20,
+	BossArmor:    0.6,
+}
+
+var presets = []Preset{normal}
+
+// Load reads the presets from path. On error the built-in normal preset
+// stays the only one.
+func Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var l []Preset
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	for _, p := range l {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	if len(l) == 0 {
+		return fmt.Errorf("no difficulty presets in %s", path)
+	}
+	presets = l
+	return nil
+}
+
+func (p Preset) validate() error {
+	switch {
+	case p.ID == "":
+		return fmt.Errorf("difficulty preset without id")
+	case p.SpawnMin < 1 || p.SpawnMax < p.SpawnMin:
+		return fmt.Errorf("difficulty %q: bad spawn range %d..%d", p.ID, p.SpawnMin, p.SpawnMax)
+	case p.MobHealth <= 0 || p.BossHealth <= 0 || p.PlayerHealth <= 0:
+		return fmt.Errorf("difficulty %q: health must be positive", p.ID)
+	case p.BossArmor <= 0:
+		return fmt.Errorf("difficulty %q: bossArmor must be positive", p.ID)
+	}
+	return nil
+}
+
+// All lists the presets in the order of the file, easiest first.
+func All() []Preset {
+	return presets
+}
+
+// Get looks up a preset by ID. Unknown IDs get the default preset, or the
+// first one if the file has no default.
+func Get(id string) Preset {
+	for _, p := range presets {
+		if p.ID == id {
+			return p
+		}
+	}
+	for _, p := range presets {
+		if p.ID == Default {
+			return p
+		}
+	}
+	return presets[0]
+}
@@ -0,0 +1,101 @@
+package dungeon
+
+import (
+	"math/rand"
+)
+
+// Leaves of the BSP tree are split until they are at most bspMaxLeaf tiles
+// on a side, and never below bspMinLeaf. Both grow with the largest room of
+// the profile.
+const (
+	bspMinLeaf = 8
+	bspMaxLeaf = 14
+)
+
+type bspLeaves struct {
+	min, max int
+}
+
+// BSPGenerator splits the level into a tree of rectangles and puts a room in
+// every leaf. Rooms end up evenly spread, unlike the scattered rooms of
+// RoomsGenerator.
+type BSPGenerator struct{}
+
+func (BSPGenerator) Generate(r *rand.Rand, p Profile) Layout {
+	t := solidTiles(p.W, p.H)
+	leaves := bspLeaves{
+		min: max(bspMinLeaf, p.MinRoom+2),
+		max: max(bspMaxLeaf, p.MaxRoom+6),
+	}
+	// Keep the outer ring solid
+	var placed []PlacedPrefab
+	rooms := bspSplit(t, r, p, leaves, Room{X: 1, Y: 1, W: p.W - 2, H: p.H - 2}, &placed)
+
+	if len(rooms) == 0 {
+		return openLayout(t, r, 3, 1)
+	}
+	connectRooms(t, r, rooms, placed, p.Corridor)
+
+	spawn := centerOf(rooms[0])
+	return Layout{Tiles: t, Rooms: rooms, Spawn: spawn, Exit: farthestRoom(t, spawn, rooms), Prefabs: placed}
+}
+
+// bspSplit carves the rooms of leaf and everything below it and returns
+// them, still unconnected. Prefabs among them are added to placed.
+func bspSplit(t [][]int, r *rand.Rand, p Profile, leaves bspLeaves, leaf Room, placed *[]PlacedPrefab) []Room {
+	canSplitX := leaf.W >= 2*leaves.min
+	canSplitY := leaf.H >= 2*leaves.min
+	small := leaf.W <= leaves.max && leaf.H <= leaves.max
+	if (!canSplitX && !canSplitY) || (small && r.Intn(3) == 0) {
+		return bspRoom(t, r, p, leaf, placed)
+	}
+
+	// Split across the longer side so leaves stay roughly square
+	splitX := canSplitX
+	if canSplitX && canSplitY {
+		splitX = leaf.W > leaf.H || (leaf.W == leaf.H && r.Intn(2) == 0)
+	}
+
+	var a, b Room
+	if splitX {
+		at := leaves.min + r.Intn(leaf.W-2*leaves.min+1)
+		a = Room{X: leaf.X, Y: leaf.Y, W: at, H: leaf.H}
+		b = Room{X: leaf.X + at, Y: leaf.Y, W: leaf.W - at, H: leaf.H}
+	} else {
+		at := leaves.min + r.Intn(leaf.H-2*leaves.min+1)
+		a = Room{X: leaf.X, Y: leaf.Y, W: leaf.W, H: at}
+		b = Room{X: leaf.X, Y: leaf.Y + at, W: leaf.W, H: leaf.H - at}
+	}
+
+	return append(bspSplit(t, r, p, leaves, a, placed), bspSplit(t, r, p, leaves, b, placed)...)
+}
+
+// bspRoom carves a random room or places a prefab inside leaf, keeping a
+// wall between it and the neighbouring leaves.
+func bspRoom(t [][]int, r *rand.Rand, p Profile, leaf Room, placed *[]PlacedPrefab) []Room {
+	if pf := pickPrefab(r, min(p.MaxRoom+prefabSlack, leaf.W-2), min(p.MaxRoom+prefabSlack, leaf.H-2)); pf != nil {
+		rm := Room{
+			X: leaf.X + 1 + r.Intn(leaf.W-pf.W-1),
+			Y: leaf.Y + 1 + r.Intn(leaf.H-pf.H-1),
+			W: pf.W,
+			H: pf.H,
+		}
+		*placed = append(*placed, PlacedPrefab{Prefab: pf, X: rm.X, Y: rm.Y})
+		return []Room{rm}
+	}
+	maxW := min(p.MaxRoom, leaf.W-2)
+	maxH := min(p.MaxRoom, leaf.H-2)
+	if maxW < p.MinRoom || maxH < p.MinRoom {
+		return nil
+	}
+	rw := p.MinRoom + r.Intn(maxW-p.MinRoom+1)
+	rh := p.MinRoom + r.Intn(maxH-p.MinRoom+1)
+	rm := Room{
+		X: leaf.X + 1 + r.Intn(leaf.W-rw-1),
+		Y: leaf.Y + 1 + r.Intn(leaf.H-rh-1),
+		W: rw,
+		H: rh,
+	}
+	carveRoom(t, rm)
+	return []Room{rm}
+}
@@ -0,0 +1,171 @@
+package dungeon
+
+import (
+	"math/rand"
+)
+
+const (
+	// caveFill is the share of walls the noise starts with; caveSteps
+	// smoothing passes turn it into caverns.
+	caveFill  = 0.45
+	caveSteps = 5
+	// caveAttempts new caves are tried when the biggest cavern is smaller
+	// than caveMinFloor of the map.
+	caveAttempts = 5
+	caveMinFloor = 0.3
+
+	// drunkardFloor is the share of the map the walker digs out.
+	drunkardFloor = 0.35
+)
+
+// CaveGenerator grows natural caverns with a cellular automaton. Only the
+// largest cavern is kept so every floor tile can be reached.
+type CaveGenerator struct{}
+
+func (CaveGenerator) Generate(r *rand.Rand, p Profile) Layout {
+	w, h := p.W, p.H
+	var t [][]int
+	for i := 0; i < caveAttempts; i++ {
+		t = solidTiles(w, h)
+		for y := 1; y < h-1; y++ {
+			for x := 1; x < w-1; x++ {
+				if r.Float64() >= caveFill {
+					t[y][x] = 0
+				}
+			}
+		}
+		for s := 0; s < caveSteps; s++ {
+			t = smoothCave(t)
+		}
+		if keepLargestCavern(t) >= int(caveMinFloor*float64(w*h)) {
+			break
+		}
+	}
+	return openLayout(t, r, 4, p.Rooms)
+}
+
+// smoothCave turns a tile into wall when most of its neighbours are walls
+// and into floor when most are floor. The outer ring stays solid.
+func smoothCave(t [][]int) [][]int {
+	h, w := len(t), len(t[0])
+	next := solidTiles(w, h)
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			walls := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && t[y+dy][x+dx] != 0 {
+						walls++
+					}
+				}
+			}
+			switch {
+			case walls >= 5:
+				next[y][x] = 1
+			case walls <= 3:
+				next[y][x] = 0
+			default:
+				next[y][x] = t[y][x]
+			}
+		}
+	}
+	return next
+}
+
+// keepLargestCavern fills every cavern but the largest with wall and
+// returns its size in tiles.
+func keepLargestCavern(t [][]int) int {
+	h, w := len(t), len(t[0])
+	region := make([][]int, h)
+	for y := range region {
+		region[y] = make([]int, w)
+	}
+
+	sizes := []int{0}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if t[y][x] != 0 || region[y][x] != 0 {
+				continue
+			}
+			id := len(sizes)
+			size := 0
+			for row, ds := range floorDistances(t, x, y) {
+				for col, d := range ds {
+					if d >= 0 {
+						region[row][col] = id
+						size++
+					}
+				}
+			}
+			sizes = append(sizes, size)
+		}
+	}
+
+	largest := 0
+	for id, size := range sizes {
+		if size > sizes[largest] {
+			largest = id
+		}
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if t[y][x] == 0 && region[y][x] != largest {
+				t[y][x] = 1
+			}
+		}
+	}
+	return sizes[largest]
+}
+
+// DrunkardGenerator digs winding tunnels with a random walk from the middle
+// of the map, now and then opening up a small chamber.
+type DrunkardGenerator struct{}
+
+func (DrunkardGenerator) Generate(r *rand.Rand, p Profile) Layout {
+	w, h := p.W, p.H
+	t := solidTiles(w, h)
+	x, y := w/2, h/2
+	target := int(drunkardFloor * float64((w-2)*(h-2)))
+
+	dirs := [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	dir := dirs[r.Intn(4)]
+	carved := 0
+	dig := func(x, y int) {
+		if t[y][x] != 0 {
+			t[y][x] = 0
+			carved++
+		}
+	}
+
+	for steps := 0; carved < target && steps < w*h*20; steps++ {
+		// Tunnels are as wide as the corridors of the other generators
+		for i := 0; i < p.Corridor; i++ {
+			if dir[0] != 0 && y+i < h-1 {
+				dig(x, y+i)
+			} else if dir[1] != 0 && x+i < w-1 {
+				dig(x+i, y)
+			}
+		}
+		if r.Intn(40) == 0 {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if x+dx > 0 && y+dy > 0 && x+dx < w-1 && y+dy < h-1 {
+						dig(x+dx, y+dy)
+					}
+				}
+			}
+		}
+
+		// Mostly keep walking the same way for longer tunnels
+		if r.Intn(3) == 0 {
+			dir = dirs[r.Intn(4)]
+		}
+		nx, ny := x+dir[0], y+dir[1]
+		if nx < 1 || ny < 1 || nx >= w-1 || ny >= h-1 {
+			dir = dirs[r.Intn(4)]
+			continue
+		}
+		x, y = nx, ny
+	}
+	return openLayout(t, r, 3, p.Rooms)
+}
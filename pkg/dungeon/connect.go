@@ -0,0 +1,187 @@
+package dungeon
+
+import (
+	"math/rand"
+	"sort"
+)
+
+const (
+	// A level needs at least minLevelRooms rooms and minFloorShare of its
+	// tiles as floor. Layouts that fall short or can't be walked from spawn
+	// to exit are generated again, up to layoutAttempts times.
+	minLevelRooms  = 4
+	minFloorShare  = 0.2
+	layoutAttempts = 10
+
+	// loopShare of the rooms get an extra corridor on top of the spanning
+	// tree, so not every level is a dead-end tree.
+	loopShare = 0.25
+)
+
+type roomEdge struct {
+	a, b, length int
+}
+
+// connectRooms joins rooms with corridors along a minimum spanning tree of
+// their centers, so every room can be reached on the shortest total
+// corridor length, plus a few short extra corridors that close loops.
+// Corridors lead into prefabs through their doors and are walled off
+// where they cross one.
+func connectRooms(t [][]int, r *rand.Rand, rooms []Room, placed []PlacedPrefab, width int) {
+	defer stampPrefabs(t, placed)
+
+	if len(rooms) < 2 {
+		return
+	}
+
+	dist := func(a, b int) int {
+		ax, ay := rooms[a].Center()
+		bx, by := rooms[b].Center()
+		return abs(ax-bx) + abs(ay-by)
+	}
+
+	// Prim's algorithm; n is small enough for the quadratic version
+	inTree := make([]bool, len(rooms))
+	best := make([]roomEdge, len(rooms))
+	inTree[0] = true
+	for i := 1; i < len(rooms); i++ {
+		best[i] = roomEdge{a: 0, b: i, length: dist(0, i)}
+	}
+	tree := map[[2]int]bool{}
+	for n := 1; n < len(rooms); n++ {
+		next := -1
+		for i := range rooms {
+			if !inTree[i] && (next < 0 || best[i].length < best[next].length) {
+				next = i
+			}
+		}
+		e := best[next]
+		inTree[next] = true
+		tree[[2]int{min(e.a, e.b), max(e.a, e.b)}] = true
+		carveEdge(t, r, rooms, placed, e, width)
+
+		for i := range rooms {
+			if d := dist(next, i); !inTree[i] && d < best[i].length {
+				best[i] = roomEdge{a: next, b: i, length: d}
+			}
+		}
+	}
+
+	// Loops come from the shorter of the remaining edges so they don't cut
+	// across the whole level
+	var extra []roomEdge
+	for a := range rooms {
+		for b := a + 1; b < len(rooms); b++ {
+			if !tree[[2]int{a, b}] {
+				extra = append(extra, roomEdge{a: a, b: b, length: dist(a, b)})
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].length < extra[j].length })
+	loops := int(loopShare * float64(len(rooms)))
+	extra = extra[:min(len(extra), 2*loops)]
+	r.Shuffle(len(extra), func(i, j int) { extra[i], extra[j] = extra[j], extra[i] })
+	for _, e := range extra[:min(len(extra), loops)] {
+		carveEdge(t, r, rooms, placed, e, width)
+	}
+}
+
+func carveEdge(t [][]int, r *rand.Rand, rooms []Room, placed []PlacedPrefab, e roomEdge, width int) {
+	a := roomEntry(rooms[e.a], placed, centerOf(rooms[e.b]))
+	b := roomEntry(rooms[e.b], placed, centerOf(rooms[e.a]))
+	carveL(t, r, a[0], a[1], b[0], b[1], width)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// farthestRoom is the center of the room the longest walk away from spawn.
+func farthestRoom(t [][]int, spawn [2]int, rooms []Room) [2]int {
+	dist := floorDistances(t, spawn[0], spawn[1])
+	exit := spawn
+	best := -1
+	for _, rm := range rooms {
+		c := centerOf(rm)
+		if d := dist[c[1]][c[0]]; d > best {
+			exit, best = c, d
+		}
+	}
+	return exit
+}
+
+// layoutOK reports whether l is worth playing: big enough, with enough
+// rooms, and with the exit and every room reachable from the spawn.
+func layoutOK(l Layout) bool {
+	if len(l.Rooms) < minLevelRooms {
+		return false
+	}
+
+	floor := 0
+	for _, row := range l.Tiles {
+		for _, t := range row {
+			if t == 0 {
+				floor++
+			}
+		}
+	}
+	if float64(floor) < minFloorShare*float64(len(l.Tiles)*len(l.Tiles[0])) {
+		return false
+	}
+
+	dist := floorDistances(l.Tiles, l.Spawn[0], l.Spawn[1])
+	if dist[l.Exit[1]][l.Exit[0]] <= 0 {
+		return false
+	}
+	for _, rm := range l.Rooms {
+		c := centerOf(rm)
+		if dist[c[1]][c[0]] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// generateLayout runs g until it makes a layout that passes layoutOK. If it
+// never does, the level falls back to plain rooms without prefabs, which
+// connectRooms always links up; those are retried until they have enough
+// rooms and floor as well.
+func generateLayout(g Generator, r *rand.Rand, p Profile) Layout {
+	if l, ok := tryLayout(g, r, p); ok {
+		return l
+	}
+	for {
+		if l, ok := tryLayout(RoomsGenerator{NoPrefabs: true}, r, p); ok {
+			return l
+		}
+	}
+}
+
+// tryLayout returns the first of up to layoutAttempts layouts from g that
+// passes layoutOK once its unreachable floor is sealed.
+func tryLayout(g Generator, r *rand.Rand, p Profile) (Layout, bool) {
+	for i := 0; i < layoutAttempts; i++ {
+		l := g.Generate(r, p)
+		sealUnreachable(l)
+		if layoutOK(l) {
+			return l, true
+		}
+	}
+	return Layout{}, false
+}
+
+// sealUnreachable walls up floor the spawn can't walk to, such as scraps of
+// a room cut off by a prefab's walls, so nothing spawns there.
+func sealUnreachable(l Layout) {
+	dist := floorDistances(l.Tiles, l.Spawn[0], l.Spawn[1])
+	for y, row := range l.Tiles {
+		for x, t := range row {
+			if t == 0 && dist[y][x] < 0 {
+				row[x] = 1
+			}
+		}
+	}
+}
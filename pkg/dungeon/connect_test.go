@@ -0,0 +1,55 @@
+package dungeon
+
+import (
+	"testing"
+
+	"spooknloot/pkg/rng"
+)
+
+// At most one in maxFallbackShare layouts may need the plain rooms fallback.
+const maxFallbackShare = 50
+
+func TestLayoutsConnected(t *testing.T) {
+	if err := LoadPrefabs("prefabs"); err != nil {
+		t.Fatal(err)
+	}
+	generators := []struct {
+		name string
+		gen  Generator
+	}{
+		{"rooms", RoomsGenerator{}},
+		{"rooms without prefabs", RoomsGenerator{NoPrefabs: true}},
+		{"bsp", BSPGenerator{}},
+		{"cave", CaveGenerator{}},
+		{"drunkard", DrunkardGenerator{}},
+		{"rooms and cave", MixedGenerator{Left: RoomsGenerator{}, Right: CaveGenerator{}}},
+		{"bsp and drunkard", MixedGenerator{Left: BSPGenerator{}, Right: DrunkardGenerator{}}},
+		{"by level", nil},
+	}
+	for _, g := range generators {
+		t.Run(g.name, func(t *testing.T) {
+			layouts, fallbacks := 0, 0
+			for seed := int64(1); seed <= 40; seed++ {
+				for _, level := range []int{1, 5, 12, 20} {
+					rng.SetSeed(seed)
+					gen := g.gen
+					if gen == nil {
+						gen = generatorFor(level)
+					}
+					layouts++
+					if _, ok := tryLayout(gen, rng.Stream("layout", level), ProfileFor(level)); !ok {
+						fallbacks++
+					}
+
+					l := generateLayout(gen, rng.Stream("layout", level), ProfileFor(level))
+					if !layoutOK(l) {
+						t.Errorf("seed %d level %d: layout is too small or not connected", seed, level)
+					}
+				}
+			}
+			if fallbacks > layouts/maxFallbackShare {
+				t.Errorf("fell back to plain rooms for %d of %d layouts", fallbacks, layouts)
+			}
+		})
+	}
+}
@@ -2,19 +2,14 @@ package dungeon
 
 import (
 	"math/rand"
-	"time"
+
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/rng"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
-const (
-	tileSize = 16
-	mapW     = 40
-	mapH     = 25
-	maxRooms = 8
-	minSize  = 4
-	maxSize  = 8
-)
+const tileSize = 16
 
 type Room struct {
 	X, Y, W, H int
@@ -41,6 +36,12 @@ var (
 	colliders         []rl.Rectangle
 	initialized       bool
 	exitVisible       bool
+
+	// Per-level random streams, see SeedLevel.
+	layoutRand *rand.Rand
+	decorRand  *rand.Rand
+	potionRand *rand.Rand
+	spawnRand  *rand.Rand
 )
 
 // Tile indices mapping for spritesheet:
@@ -50,13 +51,14 @@ func Init() {
 	if initialized {
 		return
 	}
-	dungeonTexture = rl.LoadTexture("assets/dungeon/spritesheet.png")
-	rl.SetTextureFilter(dungeonTexture, rl.FilterPoint)
-	dungeonAddTexture = rl.LoadTexture("assets/dungeon/dungeon_add.png")
-	rl.SetTextureFilter(dungeonAddTexture, rl.FilterPoint)
-	torchFrontTexture = rl.LoadTexture("assets/dungeon/torch_front.png")
-	rl.SetTextureFilter(torchFrontTexture, rl.FilterPoint)
+	dungeonTexture = backend.Current.LoadTexture("assets/dungeon/spritesheet.png")
+	backend.Current.SetTextureFilter(dungeonTexture, rl.FilterPoint)
+	dungeonAddTexture = backend.Current.LoadTexture("assets/dungeon/dungeon_add.png")
+	backend.Current.SetTextureFilter(dungeonAddTexture, rl.FilterPoint)
+	torchFrontTexture = backend.Current.LoadTexture("assets/dungeon/torch_front.png")
+	backend.Current.SetTextureFilter(torchFrontTexture, rl.FilterPoint)
 	initPotion()
+	initTraps()
 	tileSrc = rl.NewRectangle(0, 0, tileSize, tileSize)
 	tileDest = rl.NewRectangle(0, 0, tileSize, tileSize)
 	initialized = true
@@ -64,89 +66,63 @@ func Init() {
 
 func Unload() {
 	if initialized {
-		rl.UnloadTexture(dungeonTexture)
-		rl.UnloadTexture(dungeonAddTexture)
-		rl.UnloadTexture(torchFrontTexture)
+		backend.Current.UnloadTexture(dungeonTexture)
+		backend.Current.UnloadTexture(dungeonAddTexture)
+		backend.Current.UnloadTexture(torchFrontTexture)
 		unloadPotion()
+		unloadTraps()
 		initialized = false
 	}
 }
 
-func Generate() {
-	rand.Seed(time.Now().UnixNano())
-
-	// Start with solid walls (1)
-	tiles = make([][]int, mapH)
-	for y := range tiles {
-		tiles[y] = make([]int, mapW)
-		for x := range tiles[y] {
-			tiles[y][x] = 1
-		}
-	}
-
-	rooms := []Room{}
-	for i := 0; i < maxRooms; i++ {
-		w := rand.Intn(maxSize-minSize+1) + minSize
-		h := rand.Intn(maxSize-minSize+1) + minSize
-		x := rand.Intn(mapW-w-2) + 1
-		y := rand.Intn(mapH-h-2) + 1
-
-		newRoom := Room{X: x, Y: y, W: w, H: h}
+// SeedLevel derives this package's random streams for the given level from
+// the run seed. Generate calls it; the boss arena calls it directly so its
+// potions are reproducible too.
+func SeedLevel(level int) {
+	layoutRand = rng.Stream("layout", level)
+	decorRand = rng.Stream("decor", level)
+	potionRand = rng.Stream("potions", level)
+	spawnRand = rng.Stream("spawns", level)
+}
 
-		overlap := false
-		for _, other := range rooms {
-			if newRoom.Intersects(other) {
-				overlap = true
-				break
-			}
-		}
-		if overlap {
-			continue
-		}
+// Generate builds the layout for the given level with the generator and
+// profile the level picks, see generatorFor, ProfileFor and generateLayout.
+// The same run seed and level always produce the same tiles, torches and
+// potion.
+func Generate(level int) {
+	SeedLevel(level)
 
-		// Carve room to floor (0)
-		for ry := y; ry < y+h; ry++ {
-			for rx := x; rx < x+w; rx++ {
-				tiles[ry][rx] = 0
-			}
-		}
+	l := generateLayout(generatorFor(level), layoutRand, ProfileFor(level))
+	tiles = l.Tiles
+	rooms := l.Rooms
+	placedPrefabs = l.Prefabs
 
-		// Connect rooms via corridors
-		if len(rooms) > 0 {
-			prev := rooms[len(rooms)-1]
-			cx1, cy1 := prev.Center()
-			cx2, cy2 := newRoom.Center()
-			if rand.Intn(2) == 0 {
-				carveCorridor(cx1, cy1, cx2, cy1)
-				carveCorridor(cx2, cy1, cx2, cy2)
-			} else {
-				carveCorridor(cx1, cy1, cx1, cy2)
-				carveCorridor(cx1, cy2, cx2, cy2)
-			}
-		}
+	spawnPx = rl.NewVector2(float32(l.Spawn[0]*tileSize), float32(l.Spawn[1]*tileSize))
+	exitPx = rl.NewRectangle(float32(l.Exit[0]*tileSize), float32(l.Exit[1]*tileSize), tileSize, tileSize)
+	tiles[l.Exit[1]][l.Exit[0]] = 9
 
-		rooms = append(rooms, newRoom)
-	}
+	exitVisible = false
 
-	if len(rooms) > 0 {
-		sx, sy := rooms[0].Center()
-		spawnPx = rl.NewVector2(float32(sx*tileSize), float32(sy*tileSize))
+	classifyCorners()
 
-		ex, ey := rooms[len(rooms)-1].Center()
-		exitPx = rl.NewRectangle(float32(ex*tileSize), float32(ey*tileSize), tileSize, tileSize)
-		tiles[ey][ex] = 9
-	} else {
-		spawnPx = rl.NewVector2(float32(2*tileSize), float32(2*tileSize))
-		exitPx = rl.NewRectangle(float32((mapW-3)*tileSize), float32((mapH-3)*tileSize), tileSize, tileSize)
-	}
+	resetPotion()
+	SpawnPotion()
+	assignRoomTypes(rooms, level, l.Spawn, l.Exit)
+	fillPrefabs(level, l.Spawn)
+	placeLocks(rooms, level, l.Spawn)
+	placeTraps(rooms, level, l.Spawn, l.Exit)
 
-	exitVisible = false
+	buildColliders()
 
-	classifyCorners()
+	generateRoomFloorOverlays(rooms)
+	generateRoomWallTorches(rooms)
+	decoratePrefabs()
+}
 
+func buildColliders() {
 	colliders = colliders[:0]
-	for y := 0; y < mapH; y++ {
-		for x := 0; x < mapW; x++ {
+	for y := 0; y < len(tiles); y++ {
+		for x := 0; x < len(tiles[y]); x++ {
 			t := tiles[y][x]
 			if t > 0 && t != 9 { // 1..8 are walls
 				r := rl.NewRectangle(float32(x*tileSize), float32(y*tileSize), tileSize, tileSize)
@@ -154,19 +130,16 @@ func Generate() {
 			}
 		}
 	}
-
-	generateRoomFloorOverlays(rooms)
-	generateRoomWallTorches(rooms)
-	resetPotion()
-	SpawnPotion()
+	colliders = append(colliders, sealedColliders()...)
+	colliders = append(colliders, lockedColliders()...)
 }
 
-func carveCorridor(x1, y1, x2, y2 int) {
-	// Carve a corridor that is 2 tiles wide.
-	// We thicken the path by carving the primary line and the adjacent tile to the
+func carveCorridor(tiles [][]int, x1, y1, x2, y2, width int) {
+	// Carve a corridor that is width tiles wide.
+	// We thicken the path by carving the primary line and the adjacent tiles to the
 	// positive axis direction (right for vertical corridors, down for horizontal).
 	// Bounds checks prevent writing outside the map.
-	inBounds := func(x, y int) bool { return x >= 0 && y >= 0 && x < mapW && y < mapH }
+	inBounds := func(x, y int) bool { return x >= 0 && y >= 0 && y < len(tiles) && x < len(tiles[y]) }
 
 	if x1 == x2 {
 		// Vertical corridor at column x1 from y1..y2
@@ -174,11 +147,10 @@ func carveCorridor(x1, y1, x2, y2 int) {
 			y1, y2 = y2, y1
 		}
 		for y := y1; y <= y2; y++ {
-			if inBounds(x1, y) {
-				tiles[y][x1] = 0
-			}
-			if inBounds(x1+1, y) {
-				tiles[y][x1+1] = 0
+			for i := 0; i < width; i++ {
+				if inBounds(x1+i, y) {
+					tiles[y][x1+i] = 0
+				}
 			}
 		}
 	} else if y1 == y2 {
@@ -187,17 +159,17 @@ func carveCorridor(x1, y1, x2, y2 int) {
 			x1, x2 = x2, x1
 		}
 		for x := x1; x <= x2; x++ {
-			if inBounds(x, y1) {
-				tiles[y1][x] = 0
-			}
-			if inBounds(x, y1+1) {
-				tiles[y1+1][x] = 0
+			for i := 0; i < width; i++ {
+				if inBounds(x, y1+i) {
+					tiles[y1+i][x] = 0
+				}
 			}
 		}
 	}
 }
 
 func classifyCorners() {
+	mapW, mapH := mapSize()
 	original := make([][]int, mapH)
 	for y := 0; y < mapH; y++ {
 		original[y] = make([]int, mapW)
@@ -295,8 +267,8 @@ func Draw() {
 	tex := dungeonTexture
 	texColumns := tex.Width / int32(tileSize)
 
-	for y := 0; y < mapH; y++ {
-		for x := 0; x < mapW; x++ {
+	for y := range tiles {
+		for x := range tiles[y] {
 			t := tiles[y][x]
 			if t < 0 {
 				continue
@@ -322,11 +294,26 @@ func Draw() {
 
 	drawFloorOverlays()
 
+	drawTraps()
+
+	drawSpecialRooms()
+
+	drawLocks()
+
 	DrawPotion()
 
 	drawWallTorches()
 }
 
+// mapSize is the size of the current level in tiles. Levels grow with
+// depth, see ProfileFor.
+func mapSize() (w, h int) {
+	if len(tiles) == 0 {
+		return 0, 0
+	}
+	return len(tiles[0]), len(tiles)
+}
+
 func GetColliders() []rl.Rectangle {
 	return colliders
 }
@@ -335,6 +322,10 @@ func GetSpawnPosition() rl.Vector2 {
 	return spawnPx
 }
 
+func GetExitRect() rl.Rectangle {
+	return exitPx
+}
+
 func IsPlayerAtExit(playerHitbox rl.Rectangle) bool {
 	if !exitVisible {
 		return false
@@ -345,15 +336,24 @@ func IsPlayerAtExit(playerHitbox rl.Rectangle) bool {
 		playerHitbox.Y+playerHitbox.Height > exitPx.Y
 }
 
+// GetRandomFloorPositions picks n distinct floor tiles (in pixels) from the
+// level's spawn stream, outside safe rooms and ambushes.
 func GetRandomFloorPositions(n int) []rl.Vector2 {
-	if len(tiles) == 0 || n <= 0 {
+	return randomFloorPositions(spawnRand, n, noSpawn)
+}
+
+// randomFloorPositions picks n distinct floor tiles, leaving out those skip
+// reports if it isn't nil.
+func randomFloorPositions(r *rand.Rand, n int, skip func(x, y int) bool) []rl.Vector2 {
+	if len(tiles) == 0 || n <= 0 || r == nil {
 		return nil
 	}
 
+	mapW, mapH := mapSize()
 	floorTiles := make([]rl.Vector2, 0, mapW*mapH)
 	for y := 0; y < mapH; y++ {
 		for x := 0; x < mapW; x++ {
-			if tiles[y][x] == 0 { // floor
+			if tiles[y][x] == 0 && (skip == nil || !skip(x, y)) { // floor
 				px := float32(x * tileSize)
 				py := float32(y * tileSize)
 				floorTiles = append(floorTiles, rl.NewVector2(px, py))
@@ -365,7 +365,7 @@ func GetRandomFloorPositions(n int) []rl.Vector2 {
 		return nil
 	}
 
-	rand.Shuffle(len(floorTiles), func(i, j int) { floorTiles[i], floorTiles[j] = floorTiles[j], floorTiles[i] })
+	r.Shuffle(len(floorTiles), func(i, j int) { floorTiles[i], floorTiles[j] = floorTiles[j], floorTiles[i] })
 	if n > len(floorTiles) {
 		n = len(floorTiles)
 	}
@@ -402,6 +402,9 @@ func generateRoomFloorOverlays(rooms []Room) {
 	spawnTileY := int(spawnPx.Y) / tileSize
 
 	for _, r := range rooms {
+		if isPrefab(r) {
+			continue
+		}
 		// Collect candidate floor tiles inside this room
 		candidates := make([][2]int, 0, r.W*r.H)
 		for y := r.Y; y < r.Y+r.H; y++ {
@@ -418,15 +421,18 @@ func generateRoomFloorOverlays(rooms []Room) {
 			continue
 		}
 
-		rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		decorRand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
 		count := 7
+		if t := typeOf(r); t != nil && t.Overlays > 0 {
+			count = t.Overlays
+		}
 		if count > len(candidates) {
 			count = len(candidates)
 		}
 
 		// Prepare shuffled tile order (0..3), reshuffle when exhausted to keep variety
 		base := []int{0, 1, 2, 3}
-		rand.Shuffle(len(base), func(i, j int) { base[i], base[j] = base[j], base[i] })
+		decorRand.Shuffle(len(base), func(i, j int) { base[i], base[j] = base[j], base[i] })
 		idx := 0
 
 		for i := 0; i < count; i++ {
@@ -437,7 +443,7 @@ func generateRoomFloorOverlays(rooms []Room) {
 			idx++
 			if idx == len(base) {
 				prev := base[len(base)-1]
-				rand.Shuffle(len(base), func(i, j int) { base[i], base[j] = base[j], base[i] })
+				decorRand.Shuffle(len(base), func(i, j int) { base[i], base[j] = base[j], base[i] })
 				if len(base) > 1 && base[0] == prev {
 					base[0], base[1] = base[1], base[0]
 				}
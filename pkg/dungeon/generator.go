@@ -0,0 +1,346 @@
+package dungeon
+
+import (
+	"math/rand"
+
+	"spooknloot/pkg/rng"
+)
+
+// Layout is what a Generator produces. Tiles holds 0 for floor and 1 for
+// wall; Generate turns the walls into edge and corner tiles afterwards.
+// Spawn and Exit are tile coordinates on the floor.
+type Layout struct {
+	Tiles [][]int
+	// Rooms get the floor overlays and torches. Generators without real
+	// rooms report open areas instead.
+	Rooms []Room
+	Spawn [2]int
+	Exit  [2]int
+	// Prefabs are the rooms that are prefabs, also listed in Rooms.
+	Prefabs []PlacedPrefab
+}
+
+// Generator carves the layout of a level shaped by p. All randomness must
+// come from r so a run seed always produces the same level.
+type Generator interface {
+	Generate(r *rand.Rand, p Profile) Layout
+}
+
+// override replaces the per-level choice of generator, see UseGenerator.
+var override Generator
+
+// UseGenerator makes every level use g. nil goes back to picking a
+// generator by level.
+func UseGenerator(g Generator) {
+	override = g
+}
+
+type weightedGenerator struct {
+	gen    Generator
+	weight int
+}
+
+// generatorWeights gives the chance of each generator on a level. The first
+// levels are mostly classic rooms; deeper down caves and tunnels take over.
+func generatorWeights(level int) []weightedGenerator {
+	return []weightedGenerator{
+		{RoomsGenerator{}, max(1, 6-level/2)},
+		{BSPGenerator{}, 3},
+		{CaveGenerator{}, min(level/3, 4)},
+		{DrunkardGenerator{}, min(level/4, 3)},
+	}
+}
+
+// Levels from mixFromLevel on may be split into two halves made by
+// different generators, with a chance of 1 in mixChance.
+const (
+	mixFromLevel = 6
+	mixChance    = 3
+)
+
+// generatorFor picks the generator of a level from its own stream, so the
+// choice doesn't change the layout stream.
+func generatorFor(level int) Generator {
+	if override != nil {
+		return override
+	}
+	r := rng.Stream("generator", level)
+	weights := generatorWeights(level)
+	if level >= mixFromLevel && r.Intn(mixChance) == 0 {
+		left := pickGenerator(r, weights)
+		right := pickGenerator(r, weights)
+		return MixedGenerator{Left: left, Right: right}
+	}
+	return pickGenerator(r, weights)
+}
+
+func pickGenerator(r *rand.Rand, weights []weightedGenerator) Generator {
+	total := 0
+	for _, w := range weights {
+		total += w.weight
+	}
+	n := r.Intn(total)
+	for _, w := range weights {
+		if n < w.weight {
+			return w.gen
+		}
+		n -= w.weight
+	}
+	return weights[0].gen
+}
+
+// RoomsGenerator scatters non-overlapping rooms and joins them with
+// L-shaped corridors, see connectRooms. Some rooms are prefabs unless
+// NoPrefabs is set.
+type RoomsGenerator struct {
+	NoPrefabs bool
+}
+
+// Every room gets roomAttempts tries to find a free spot.
+const roomAttempts = 5
+
+func (g RoomsGenerator) Generate(r *rand.Rand, p Profile) Layout {
+	t := solidTiles(p.W, p.H)
+
+	rooms := []Room{}
+	var placed []PlacedPrefab
+	for i := 0; i < p.Rooms*roomAttempts && len(rooms) < p.Rooms; i++ {
+		var pf *Prefab
+		if !g.NoPrefabs {
+			pf = pickPrefab(r, min(p.MaxRoom+prefabSlack, p.W-4), min(p.MaxRoom+prefabSlack, p.H-4))
+		}
+		var newRoom Room
+		if pf != nil {
+			// Leave space for the corridors in front of its doors
+			newRoom = Room{X: r.Intn(p.W-pf.W-3) + 2, Y: r.Intn(p.H-pf.H-3) + 2, W: pf.W, H: pf.H}
+		} else {
+			rw := r.Intn(p.MaxRoom-p.MinRoom+1) + p.MinRoom
+			rh := r.Intn(p.MaxRoom-p.MinRoom+1) + p.MinRoom
+			x := r.Intn(p.W-rw-2) + 1
+			y := r.Intn(p.H-rh-2) + 1
+			newRoom = Room{X: x, Y: y, W: rw, H: rh}
+		}
+
+		overlap := false
+		for _, other := range rooms {
+			if newRoom.Intersects(other) {
+				overlap = true
+				break
+			}
+		}
+		if overlap {
+			continue
+		}
+
+		if pf != nil {
+			placed = append(placed, PlacedPrefab{Prefab: pf, X: newRoom.X, Y: newRoom.Y})
+		} else {
+			carveRoom(t, newRoom)
+		}
+		rooms = append(rooms, newRoom)
+	}
+	connectRooms(t, r, rooms, placed, p.Corridor)
+
+	l := Layout{Tiles: t, Rooms: rooms, Prefabs: placed}
+	if len(rooms) > 0 {
+		l.Spawn = centerOf(rooms[0])
+		l.Exit = farthestRoom(t, l.Spawn, rooms)
+	} else {
+		l.Spawn = [2]int{2, 2}
+		l.Exit = [2]int{p.W - 3, p.H - 3}
+	}
+	return l
+}
+
+// MixedGenerator builds the left and right half of a level with different
+// generators and joins them with a corridor from the left half's exit to
+// the right half's spawn.
+type MixedGenerator struct {
+	Left, Right Generator
+}
+
+func (g MixedGenerator) Generate(r *rand.Rand, p Profile) Layout {
+	lw := p.W / 2
+	half := p
+	half.Rooms = (p.Rooms + 1) / 2
+	half.W = lw
+	left := g.Left.Generate(r, half)
+	half.W = p.W - lw
+	right := g.Right.Generate(r, half)
+
+	t := solidTiles(p.W, p.H)
+	for y := 0; y < p.H; y++ {
+		copy(t[y], left.Tiles[y])
+		copy(t[y][lw:], right.Tiles[y])
+	}
+
+	rooms := append([]Room(nil), left.Rooms...)
+	for _, rm := range right.Rooms {
+		rm.X += lw
+		rooms = append(rooms, rm)
+	}
+	placed := append([]PlacedPrefab(nil), left.Prefabs...)
+	for _, pp := range right.Prefabs {
+		pp.X += lw
+		placed = append(placed, pp)
+	}
+
+	exit := [2]int{right.Exit[0] + lw, right.Exit[1]}
+	carveL(t, r, left.Exit[0], left.Exit[1], right.Spawn[0]+lw, right.Spawn[1], p.Corridor)
+	stampPrefabs(t, placed)
+
+	return Layout{Tiles: t, Rooms: rooms, Spawn: left.Spawn, Exit: exit, Prefabs: placed}
+}
+
+func solidTiles(w, h int) [][]int {
+	t := make([][]int, h)
+	for y := range t {
+		t[y] = make([]int, w)
+		for x := range t[y] {
+			t[y][x] = 1
+		}
+	}
+	return t
+}
+
+func carveRoom(t [][]int, rm Room) {
+	for y := rm.Y; y < rm.Y+rm.H; y++ {
+		for x := rm.X; x < rm.X+rm.W; x++ {
+			t[y][x] = 0
+		}
+	}
+}
+
+func centerOf(rm Room) [2]int {
+	x, y := rm.Center()
+	return [2]int{x, y}
+}
+
+// carveL joins two tiles with an L-shaped corridor, bending at either end
+// at random.
+func carveL(t [][]int, r *rand.Rand, x1, y1, x2, y2, width int) {
+	if r.Intn(2) == 0 {
+		carveCorridor(t, x1, y1, x2, y1, width)
+		carveCorridor(t, x2, y1, x2, y2, width)
+	} else {
+		carveCorridor(t, x1, y1, x1, y2, width)
+		carveCorridor(t, x1, y2, x2, y2, width)
+	}
+}
+
+// floorDistances is the walking distance in tiles from (sx, sy) to every
+// tile, -1 for walls and floor that can't be reached.
+func floorDistances(t [][]int, sx, sy int) [][]int {
+	h, w := len(t), len(t[0])
+	dist := make([][]int, h)
+	for y := range dist {
+		dist[y] = make([]int, w)
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	if t[sy][sx] != 0 {
+		return dist
+	}
+
+	dist[sy][sx] = 0
+	queue := [][2]int{{sx, sy}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			x, y := p[0]+d[0], p[1]+d[1]
+			if x < 0 || y < 0 || x >= w || y >= h || t[y][x] != 0 || dist[y][x] >= 0 {
+				continue
+			}
+			dist[y][x] = dist[p[1]][p[0]] + 1
+			queue = append(queue, [2]int{x, y})
+		}
+	}
+	return dist
+}
+
+// farthestFloor is the floor tile with the longest walk from (sx, sy).
+func farthestFloor(t [][]int, sx, sy int) [2]int {
+	best := [2]int{sx, sy}
+	bestDist := 0
+	for y, row := range floorDistances(t, sx, sy) {
+		for x, d := range row {
+			if d > bestDist {
+				best, bestDist = [2]int{x, y}, d
+			}
+		}
+	}
+	return best
+}
+
+// pockets finds up to n separate size by size squares of open floor. The
+// generators that don't carve rooms use them as rooms.
+func pockets(t [][]int, r *rand.Rand, size, n int) []Room {
+	h, w := len(t), len(t[0])
+	open := func(x, y int) bool {
+		for yy := y; yy < y+size; yy++ {
+			for xx := x; xx < x+size; xx++ {
+				if t[yy][xx] != 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	var candidates []Room
+	for y := 0; y+size <= h; y++ {
+		for x := 0; x+size <= w; x++ {
+			if open(x, y) {
+				candidates = append(candidates, Room{X: x, Y: y, W: size, H: size})
+			}
+		}
+	}
+	r.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	var rooms []Room
+	for _, c := range candidates {
+		if len(rooms) == n {
+			break
+		}
+		overlap := false
+		for _, o := range rooms {
+			if c.Intersects(o) {
+				overlap = true
+				break
+			}
+		}
+		if !overlap {
+			rooms = append(rooms, c)
+		}
+	}
+	return rooms
+}
+
+// randomFloor picks any floor tile.
+func randomFloor(t [][]int, r *rand.Rand) [2]int {
+	var floor [][2]int
+	for y := range t {
+		for x := range t[y] {
+			if t[y][x] == 0 {
+				floor = append(floor, [2]int{x, y})
+			}
+		}
+	}
+	if len(floor) == 0 {
+		return [2]int{len(t[0]) / 2, len(t) / 2}
+	}
+	return floor[r.Intn(len(floor))]
+}
+
+// openLayout finishes a layout without real rooms: spawn in one of the
+// open areas and the exit as far away from it as possible.
+func openLayout(t [][]int, r *rand.Rand, pocketSize, maxPockets int) Layout {
+	rooms := pockets(t, r, pocketSize, maxPockets)
+	spawn := randomFloor(t, r)
+	if len(rooms) > 0 {
+		spawn = centerOf(rooms[0])
+	}
+	return Layout{Tiles: t, Rooms: rooms, Spawn: spawn, Exit: farthestFloor(t, spawn[0], spawn[1])}
+}
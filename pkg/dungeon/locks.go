@@ -0,0 +1,450 @@
+package dungeon
+
+import (
+	"math/rand"
+
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/rng"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// LockKind is what opens a locked room.
+type LockKind int
+
+const (
+	// LockChest has its key in a small chest somewhere else on the level.
+	LockChest LockKind = iota + 1
+	// LockMob has its key carried by one of the mobs, which drops it.
+	LockMob
+	// LockLever opens when a lever on the floor is pulled.
+	LockLever
+)
+
+const (
+	lockMinLevel = 3
+	maxLocks     = 3
+	// Only rooms with at most this many open tiles around them get locked.
+	maxLockEntrances = 6
+	// Chests, levers and key carriers are this many tiles' walk from spawn
+	// or more.
+	lockSpawnSpace = 4
+	// The player opens a door from this many pixels away.
+	doorReach = 4
+)
+
+// lock is a room whose entrances stay shut until its key is brought to one
+// of them or its lever is pulled.
+type lock struct {
+	doors [][2]int
+	kind  LockKind
+	// at is the tile of the chest or the lever, or for LockMob the tile
+	// the key lies on once it is dropped; holder is the carrier's mob index.
+	at     [2]int
+	holder int
+
+	// used is set once the chest is open, the lever pulled or the carrier
+	// dead; dropped while the key lies on the floor, held once the player
+	// has it.
+	used    bool
+	dropped bool
+	held    bool
+	open    bool
+}
+
+var locks []lock
+
+// Each lock has its colour, on its doors and its key.
+var lockColors = []rl.Color{
+	rl.NewColor(230, 190, 80, 255),
+	rl.NewColor(120, 190, 230, 255),
+	rl.NewColor(210, 90, 90, 255),
+}
+
+func resetLocks() {
+	locks = nil
+}
+
+// lockCount is how many rooms of a level are locked.
+func lockCount(level int) int {
+	if level < lockMinLevel {
+		return 0
+	}
+	return min((level-1)/2, maxLocks)
+}
+
+// placeLocks locks some rooms of a fresh level from its own stream and puts
+// their keys and levers where the player can get at them: the locks are
+// taken in the order the player can reach their doors from the spawn, and
+// every key or lever lies in the part of the level that is open once the
+// locks before it are.
+func placeLocks(rooms []Room, level int, spawn [2]int) {
+	resetLocks()
+	n := lockCount(level)
+	if n == 0 {
+		return
+	}
+
+	r := rng.Stream("locks", level)
+	order := r.Perm(len(rooms))
+	closed := map[[2]int]bool{}
+	var picked []lock
+	for _, i := range order {
+		if len(picked) == n {
+			break
+		}
+		rm := rooms[i]
+		if rm.contains(spawn[0], spawn[1]) {
+			continue
+		}
+		if t := typeOf(rm); t != nil && t.Ambush > 0 {
+			continue
+		}
+		// The exit is walkable but no door, so it would be a way around
+		// the lock
+		if exitOnRing(rm) {
+			continue
+		}
+		doors := roomEntrances(rm)
+		if len(doors) == 0 || len(doors) > maxLockEntrances {
+			continue
+		}
+		ok := true
+		for _, d := range doors {
+			if closed[d] || abs(d[0]-spawn[0])+abs(d[1]-spawn[1]) < 2 {
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+		for _, d := range doors {
+			closed[d] = true
+		}
+		picked = append(picked, lock{doors: doors})
+	}
+
+	taken := map[[2]int]bool{}
+	for _, p := range potions {
+		taken[[2]int{int(p.Position.X) / tileSize, int(p.Position.Y) / tileSize}] = true
+	}
+	for len(picked) > 0 {
+		reach := reachable(spawn, closed)
+		next := -1
+		for i, l := range picked {
+			if touches(l.doors, reach) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		l := picked[next]
+		picked = append(picked[:next], picked[next+1:]...)
+		for _, d := range l.doors {
+			delete(closed, d)
+		}
+
+		l.kind = LockKind(r.Intn(3) + 1)
+		at, ok := lockSpot(r, rooms, reach, taken, l.kind == LockMob)
+		if !ok {
+			continue
+		}
+		l.at = at
+		taken[at] = true
+		locks = append(locks, l)
+	}
+}
+
+// exitOnRing reports whether the exit tile borders rm.
+func exitOnRing(rm Room) bool {
+	for y := rm.Y - 1; y <= rm.Y+rm.H; y++ {
+		for x := rm.X - 1; x <= rm.X+rm.W; x++ {
+			if rm.contains(x, y) || y < 0 || y >= len(tiles) || x < 0 || x >= len(tiles[y]) {
+				continue
+			}
+			if tiles[y][x] == 9 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reachable is the set of tiles the player can walk to from spawn with the
+// closed doors shut.
+func reachable(spawn [2]int, closed map[[2]int]bool) map[[2]int]bool {
+	seen := map[[2]int]bool{spawn: true}
+	queue := [][2]int{spawn}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			q := [2]int{p[0] + d[0], p[1] + d[1]}
+			if q[1] < 0 || q[1] >= len(tiles) || q[0] < 0 || q[0] >= len(tiles[q[1]]) || seen[q] || closed[q] {
+				continue
+			}
+			if t := tiles[q[1]][q[0]]; t != 0 && t != 9 {
+				continue
+			}
+			seen[q] = true
+			queue = append(queue, q)
+		}
+	}
+	return seen
+}
+
+func touches(doors [][2]int, reach map[[2]int]bool) bool {
+	for _, d := range doors {
+		for _, n := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			if reach[[2]int{d[0] + n[0], d[1] + n[1]}] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lockSpot picks a floor tile inside a room within reach for a chest, a
+// lever or a key carrier, away from the spawn, other lock spots, potions
+// and the middle of special rooms. Carriers also keep out of rooms mobs
+// don't spawn in.
+func lockSpot(r *rand.Rand, rooms []Room, reach, taken map[[2]int]bool, carrier bool) ([2]int, bool) {
+	spawn := [2]int{int(spawnPx.X) / tileSize, int(spawnPx.Y) / tileSize}
+	dist := floorDistances(tiles, spawn[0], spawn[1])
+	var spots [][2]int
+	for _, rm := range rooms {
+		for y := rm.Y; y < rm.Y+rm.H; y++ {
+			for x := rm.X; x < rm.X+rm.W; x++ {
+				p := [2]int{x, y}
+				if !reach[p] || tiles[y][x] != 0 || dist[y][x] < lockSpawnSpace || noTrap(x, y) {
+					continue
+				}
+				if carrier && noSpawn(x, y) {
+					continue
+				}
+				free := true
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if taken[[2]int{x + dx, y + dy}] {
+							free = false
+						}
+					}
+				}
+				if free {
+					spots = append(spots, p)
+				}
+			}
+		}
+	}
+	if len(spots) == 0 {
+		return [2]int{}, false
+	}
+	return spots[r.Intn(len(spots))], true
+}
+
+// lockTile reports whether x, y is a door, chest or lever of a lock.
+func lockTile(x, y int) bool {
+	for _, l := range locks {
+		if l.kind != LockMob && l.at == [2]int{x, y} {
+			return true
+		}
+		for _, d := range l.doors {
+			if d == [2]int{x, y} {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lockedColliders are the doors of the locks that are still shut.
+func lockedColliders() []rl.Rectangle {
+	var out []rl.Rectangle
+	for _, l := range locks {
+		if l.open {
+			continue
+		}
+		for _, d := range l.doors {
+			out = append(out, tileRect(d[0], d[1]))
+		}
+	}
+	return out
+}
+
+// SpawnKeyHolders spawns the mobs that carry keys and returns how many
+// there are. They count towards the level's mobs like the guards.
+func SpawnKeyHolders() int {
+	n := 0
+	for i := range locks {
+		l := &locks[i]
+		if l.kind != LockMob {
+			continue
+		}
+		l.holder = mobs.MobCount()
+		mobs.SpawnMobsAtPositions([]rl.Vector2{rl.NewVector2(float32(l.at[0]*tileSize), float32(l.at[1]*tileSize))}, "random")
+		n++
+	}
+	return n
+}
+
+// HeldKeys is the number of keys the player carries for doors that are
+// still shut.
+func HeldKeys() int {
+	n := 0
+	for _, l := range locks {
+		if l.held && !l.open {
+			n++
+		}
+	}
+	return n
+}
+
+// UpdateLocks hands out keys, pulls levers and opens doors the player
+// brings the key to. It reports whether the colliders changed, in which
+// case GetColliders has to be handed out again.
+func UpdateLocks(playerHitbox rl.Rectangle) bool {
+	changed := false
+	for i := range locks {
+		l := &locks[i]
+		if l.open {
+			continue
+		}
+		at := tileRect(l.at[0], l.at[1])
+
+		switch {
+		case l.kind == LockMob && !l.used:
+			// The key falls where its carrier dies
+			if mobs.IsMobAliveByIndex(l.holder) {
+				c := mobs.GetMobHitboxCenterByIndex(l.holder)
+				if x, y := int(c.X)/tileSize, int(c.Y)/tileSize; y >= 0 && y < len(tiles) && x >= 0 && x < len(tiles[y]) && tiles[y][x] == 0 {
+					l.at = [2]int{x, y}
+				}
+			} else {
+				l.used = true
+				l.dropped = true
+			}
+		case l.dropped:
+			if rl.CheckCollisionRecs(playerHitbox, at) {
+				l.dropped = false
+				l.held = true
+				audio.RestartSound("door")
+			}
+		case !l.used && rl.CheckCollisionRecs(playerHitbox, at):
+			l.used = true
+			if l.kind == LockLever {
+				l.open = true
+				changed = true
+			} else {
+				l.held = true
+			}
+			audio.RestartSound("door")
+		}
+
+		if l.held {
+			for _, d := range l.doors {
+				r := tileRect(d[0], d[1])
+				r = rl.NewRectangle(r.X-doorReach, r.Y-doorReach, r.Width+2*doorReach, r.Height+2*doorReach)
+				if rl.CheckCollisionRecs(playerHitbox, r) {
+					l.open = true
+					changed = true
+					audio.RestartSound("door")
+					break
+				}
+			}
+		}
+	}
+	if changed {
+		buildColliders()
+	}
+	return changed
+}
+
+// LockGoals are the places the player can make progress with the locks
+// right now: keys on the floor, unopened chests and levers, and the doors
+// of held keys.
+func LockGoals() []rl.Vector2 {
+	var out []rl.Vector2
+	center := func(p [2]int) rl.Vector2 {
+		return rl.NewVector2(float32(p[0]*tileSize+tileSize/2), float32(p[1]*tileSize+tileSize/2))
+	}
+	for _, l := range locks {
+		switch {
+		case l.open:
+		case l.held:
+			for _, d := range l.doors {
+				out = append(out, center(d))
+			}
+		case l.dropped, !l.used && l.kind != LockMob:
+			out = append(out, center(l.at))
+		}
+	}
+	return out
+}
+
+var (
+	leverBaseColor = rl.NewColor(80, 74, 88, 255)
+	leverRodColor  = rl.NewColor(160, 150, 140, 255)
+)
+
+func drawLocks() {
+	for i, l := range locks {
+		c := lockColors[i%len(lockColors)]
+		x, y := int32(l.at[0]*tileSize), int32(l.at[1]*tileSize)
+
+		if !l.open {
+			for _, d := range l.doors {
+				dx, dy := int32(d[0]*tileSize), int32(d[1]*tileSize)
+				rl.DrawRectangle(dx, dy, tileSize, tileSize, gateColor)
+				for bx := int32(2); bx < tileSize; bx += 5 {
+					rl.DrawRectangle(dx+bx, dy, 2, tileSize, gateBarColor)
+				}
+				rl.DrawRectangle(dx+5, dy+5, 6, 6, c)
+				rl.DrawRectangle(dx+7, dy+7, 2, 3, gateColor)
+			}
+		}
+
+		switch {
+		case l.kind == LockChest:
+			if l.used {
+				rl.DrawRectangle(x+3, y+7, 10, 7, chestColor)
+				rl.DrawRectangle(x+4, y+7, 8, 2, openChestColor)
+				rl.DrawRectangle(x+3, y+4, 10, 2, chestLidColor)
+			} else {
+				rl.DrawRectangle(x+3, y+7, 10, 7, chestColor)
+				rl.DrawRectangle(x+3, y+5, 10, 3, chestLidColor)
+				rl.DrawRectangle(x+7, y+8, 2, 2, c)
+			}
+		case l.kind == LockLever:
+			rl.DrawRectangle(x+4, y+11, 8, 3, leverBaseColor)
+			top := int32(3)
+			if l.used {
+				top = 12
+			}
+			rl.DrawLine(x+8, y+12, x+top, y+4, leverRodColor)
+			rl.DrawCircle(x+top, y+4, 2, c)
+		case l.dropped:
+			drawKey(x+4, y+4, c)
+		}
+	}
+}
+
+func drawKey(x, y int32, c rl.Color) {
+	rl.DrawCircleLines(x+2, y+2, 2, c)
+	rl.DrawRectangle(x+4, y+2, 5, 1, c)
+	rl.DrawRectangle(x+7, y+3, 1, 2, c)
+}
+
+// DrawKeyHolders marks the mobs that carry a key; the dungeon scene draws
+// it over the mobs.
+func DrawKeyHolders() {
+	for i, l := range locks {
+		if l.kind != LockMob || l.used || !mobs.IsMobAliveByIndex(l.holder) {
+			continue
+		}
+		h := mobs.GetMobHitboxByIndex(l.holder)
+		drawKey(int32(h.X+h.Width/2)-4, int32(h.Y)-14, lockColors[i%len(lockColors)])
+	}
+}
@@ -0,0 +1,123 @@
+package dungeon
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"spooknloot/pkg/rng"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+func TestLocksSolvable(t *testing.T) {
+	if err := LoadRoomTypes("rooms.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadPrefabs("prefabs"); err != nil {
+		t.Fatal(err)
+	}
+	placed := 0
+	for seed := int64(1); seed <= 30; seed++ {
+		for level := lockMinLevel; level <= 21; level += 3 {
+			rng.SetSeed(seed)
+			Generate(level)
+			placed += len(locks)
+			if err := solveLocks(); err != nil {
+				t.Errorf("seed %d level %d: %v", seed, level, err)
+			}
+		}
+	}
+	if placed == 0 {
+		t.Fatal("no level got a lock")
+	}
+}
+
+// A room that the exit tile borders can be walked into through the exit, so
+// it must not be locked.
+func TestLocksSkipExitRing(t *testing.T) {
+	rooms := []Room{
+		{X: 1, Y: 1, W: 5, H: 5},
+		{X: 10, Y: 1, W: 5, H: 5},
+	}
+	tiles = make([][]int, 12)
+	for y := range tiles {
+		tiles[y] = make([]int, 20)
+		for x := range tiles[y] {
+			tiles[y][x] = 1
+		}
+	}
+	for _, rm := range rooms {
+		carveRoom(tiles, rm)
+	}
+	carve := func(x1, y1, x2, y2 int) {
+		for y := y1; y <= y2; y++ {
+			for x := x1; x <= x2; x++ {
+				tiles[y][x] = 0
+			}
+		}
+	}
+	carve(6, 3, 9, 3)   // the door of the second room
+	carve(3, 6, 3, 9)   // down from the first room
+	carve(3, 9, 12, 9)  // across below both
+	carve(12, 7, 12, 8) // up to the exit
+	tiles[6][12] = 9
+
+	resetPotion()
+	specialRooms = specialRooms[:0]
+	placedPrefabs = nil
+	spawnPx = rl.NewVector2(1*tileSize, 1*tileSize)
+	exitPx = rl.NewRectangle(12*tileSize, 6*tileSize, tileSize, tileSize)
+	rng.SetSeed(1)
+	placeLocks(rooms, lockMinLevel, [2]int{1, 1})
+
+	for _, l := range locks {
+		for _, d := range l.doors {
+			if d == [2]int{9, 3} {
+				t.Fatalf("room bordering the exit is locked: %v", l.doors)
+			}
+		}
+	}
+	if err := solveLocks(); err != nil {
+		t.Error(err)
+	}
+}
+
+// solveLocks plays the level's locks the way a player would: starting with
+// every door shut, it opens each lock once its chest, lever or key carrier
+// is in reach. It reports a lock that never opens or an exit that stays
+// out of reach.
+func solveLocks() error {
+	spawn := [2]int{int(spawnPx.X) / tileSize, int(spawnPx.Y) / tileSize}
+	closed := map[[2]int]bool{}
+	for _, l := range locks {
+		for _, d := range l.doors {
+			closed[d] = true
+		}
+	}
+	open := make([]bool, len(locks))
+	for progress := true; progress; {
+		progress = false
+		reach := reachable(spawn, closed)
+		for i, l := range locks {
+			if open[i] || !reach[l.at] {
+				continue
+			}
+			for _, d := range l.doors {
+				delete(closed, d)
+			}
+			open[i] = true
+			progress = true
+		}
+	}
+	for i, l := range locks {
+		if !open[i] {
+			return fmt.Errorf("the key or lever of the lock on %v is behind a locked door", l.doors)
+		}
+	}
+	exit := [2]int{int(exitPx.X) / tileSize, int(exitPx.Y) / tileSize}
+	if !reachable(spawn, closed)[exit] {
+		return errors.New("the exit can't be reached")
+	}
+	return nil
+}
@@ -2,19 +2,18 @@ package dungeon
 
 import (
 	"math"
-	"math/rand"
-	"os"
 
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/backend"
 	"spooknloot/pkg/player"
+	"spooknloot/pkg/stats"
 	"spooknloot/pkg/world"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 var (
-	potionTexture    rl.Texture2D
-	drinkSound       rl.Sound
-	drinkSoundLoaded bool
+	potionTexture rl.Texture2D
 )
 
 type Potion struct {
@@ -31,25 +30,15 @@ func initPotion() {
 	if potionTexture.ID != 0 {
 		return
 	}
-	potionTexture = rl.LoadTexture("assets/dungeon/red_portion.png")
-	rl.SetTextureFilter(potionTexture, rl.FilterPoint)
-
-	if _, err := os.Stat("assets/audio/drink.mp3"); err == nil {
-		drinkSound = rl.LoadSound("assets/audio/drink.mp3")
-		rl.SetSoundVolume(drinkSound, 0.7)
-		drinkSoundLoaded = true
-	}
+	potionTexture = backend.Current.LoadTexture("assets/dungeon/red_portion.png")
+	backend.Current.SetTextureFilter(potionTexture, rl.FilterPoint)
 }
 
 func unloadPotion() {
 	if potionTexture.ID != 0 {
-		rl.UnloadTexture(potionTexture)
+		backend.Current.UnloadTexture(potionTexture)
 		potionTexture = rl.Texture2D{}
 	}
-	if drinkSoundLoaded {
-		rl.UnloadSound(drinkSound)
-		drinkSoundLoaded = false
-	}
 	resetPotion()
 }
 
@@ -58,7 +47,7 @@ func resetPotion() {
 }
 
 func SpawnPotion() {
-	positions := GetRandomFloorPositions(1)
+	positions := randomFloorPositions(potionRand, 1, nil)
 	if len(positions) == 0 {
 		resetPotion()
 		return
@@ -73,7 +62,7 @@ func SpawnPotion() {
 var potions []Potion
 
 func SpawnPotions(amount int, tiles []world.Tile, tileSizePx int) {
-	if amount <= 0 || len(tiles) == 0 || tileSizePx <= 0 {
+	if amount <= 0 || len(tiles) == 0 || tileSizePx <= 0 || potionRand == nil {
 		return
 	}
 
@@ -86,7 +75,7 @@ func SpawnPotions(amount int, tiles []world.Tile, tileSizePx int) {
 
 	// Spawn until we reach the requested amount, picking random tiles from the provided list.
 	for len(potions) < amount {
-		idx := rand.Intn(len(tiles))
+		idx := potionRand.Intn(len(tiles))
 		t := tiles[idx]
 		x := float32(t.X * tileSizePx)
 		y := float32(t.Y * tileSizePx)
@@ -153,9 +142,8 @@ func UpdatePotionPickup(playerHitbox rl.Rectangle) {
 			if heal > 0 {
 				player.TakeDamage(-heal)
 			}
-			if drinkSoundLoaded {
-				rl.PlaySound(drinkSound)
-			}
+			audio.RestartSound("drink")
+			stats.Emit(stats.Event{Kind: stats.PotionDrunk, Amount: heal})
 
 			potions = append(potions[:i], potions[i+1:]...)
 		} else {
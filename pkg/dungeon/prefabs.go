@@ -0,0 +1,349 @@
+package dungeon
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Layers of a prefab map. Floor tiles can be walked on and everything else
+// is wall; Walls puts wall back on top of floor, e.g. for pillars. Their
+// ids don't matter, the level is autotiled once the prefab is in. Doors
+// are the floor tiles on the edge where corridors come in; Mobs, Potions,
+// Torch and Decoration place what their names say, Decoration with the
+// index of a dungeon_add.png sprite as id.
+const (
+	prefabFloor      = "Floor"
+	prefabWalls      = "Walls"
+	prefabDoors      = "Doors"
+	prefabMobs       = "Mobs"
+	prefabPotions    = "Potions"
+	prefabTorch      = "Torch"
+	prefabDecoration = "Decoration"
+)
+
+const (
+	// One room in prefabChance is a prefab, if one fits.
+	prefabChance = 4
+	// A prefab may be this much bigger than the largest room of the
+	// profile, since it brings its own walls.
+	prefabSlack = 2
+	// Potion spots hold a potion with a chance of 1 in prefabPotionChance.
+	prefabPotionChance = 2
+)
+
+// prefabMap is the file format of a prefab, the JsonMap layout of the town
+// and the boss arena.
+type prefabMap struct {
+	Layers []struct {
+		Name  string       `json:"name"`
+		Tiles []world.Tile `json:"tiles"`
+	} `json:"layers"`
+	MapHeight int `json:"mapHeight"`
+	MapWidth  int `json:"mapWidth"`
+	TileSize  int `json:"tileSize"`
+}
+
+// Prefab is a hand-drawn room the generators can place instead of a
+// rectangle. Its middle tile has to be floor, like the middle of every
+// room.
+type Prefab struct {
+	Name string
+	W, H int
+
+	floor   [][]bool
+	doors   [][2]int
+	mobs    [][2]int
+	potions [][2]int
+	torches [][2]int
+	decor   [][3]int // x, y, sprite
+}
+
+// PlacedPrefab is a prefab at X, Y (its top left tile) in a layout.
+type PlacedPrefab struct {
+	Prefab *Prefab
+	X, Y   int
+}
+
+func (p PlacedPrefab) Room() Room {
+	return Room{X: p.X, Y: p.Y, W: p.Prefab.W, H: p.Prefab.H}
+}
+
+var (
+	prefabs []*Prefab
+	// placedPrefabs are the prefabs of the current level.
+	placedPrefabs []PlacedPrefab
+)
+
+// LoadPrefabs reads every .json map in dir into the prefab library.
+// Without prefabs all rooms are rectangles.
+func LoadPrefabs(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var l []*Prefab
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		p, err := loadPrefab(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		l = append(l, p)
+	}
+	prefabs = l
+	return nil
+}
+
+func loadPrefab(path string) (*Prefab, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m prefabMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if m.MapWidth < 3 || m.MapHeight < 3 || (m.TileSize != 0 && m.TileSize != tileSize) {
+		return nil, fmt.Errorf("%s: prefab needs at least 3x3 tiles of %d pixels", path, tileSize)
+	}
+
+	p := &Prefab{
+		Name:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		W:     m.MapWidth,
+		H:     m.MapHeight,
+		floor: make([][]bool, m.MapHeight),
+	}
+	for y := range p.floor {
+		p.floor[y] = make([]bool, m.MapWidth)
+	}
+
+	// Floor and walls first, the other layers are checked against them
+	layers := map[string][]world.Tile{}
+	for _, layer := range m.Layers {
+		for _, t := range layer.Tiles {
+			if t.X < 0 || t.Y < 0 || t.X >= p.W || t.Y >= p.H {
+				return nil, fmt.Errorf("%s: %s tile %d,%d outside the map", path, layer.Name, t.X, t.Y)
+			}
+		}
+		layers[layer.Name] = append(layers[layer.Name], layer.Tiles...)
+	}
+	for _, t := range layers[prefabFloor] {
+		p.floor[t.Y][t.X] = true
+	}
+	for _, t := range layers[prefabWalls] {
+		p.floor[t.Y][t.X] = false
+	}
+
+	onFloor := func(name string) ([][2]int, error) {
+		var out [][2]int
+		for _, t := range layers[name] {
+			if !p.floor[t.Y][t.X] {
+				return nil, fmt.Errorf("%s: %s tile %d,%d isn't on the floor", path, name, t.X, t.Y)
+			}
+			out = append(out, [2]int{t.X, t.Y})
+		}
+		return out, nil
+	}
+	if p.doors, err = onFloor(prefabDoors); err != nil {
+		return nil, err
+	}
+	if p.mobs, err = onFloor(prefabMobs); err != nil {
+		return nil, err
+	}
+	if p.potions, err = onFloor(prefabPotions); err != nil {
+		return nil, err
+	}
+	for _, t := range layers[prefabDecoration] {
+		id, err := strconv.Atoi(t.Id)
+		if err != nil || id < 0 || !p.floor[t.Y][t.X] {
+			return nil, fmt.Errorf("%s: decoration %d,%d needs a sprite id and floor", path, t.X, t.Y)
+		}
+		p.decor = append(p.decor, [3]int{t.X, t.Y, id})
+	}
+	for _, t := range layers[prefabTorch] {
+		p.torches = append(p.torches, [2]int{t.X, t.Y})
+	}
+
+	if len(p.doors) == 0 {
+		return nil, fmt.Errorf("%s: prefab without doors", path)
+	}
+	for _, d := range p.doors {
+		edgeX := d[0] == 0 || d[0] == p.W-1
+		edgeY := d[1] == 0 || d[1] == p.H-1
+		if edgeX == edgeY {
+			return nil, fmt.Errorf("%s: door %d,%d has to be on an edge, not a corner", path, d[0], d[1])
+		}
+	}
+	if !p.floor[p.H/2][p.W/2] {
+		return nil, fmt.Errorf("%s: the middle tile has to be floor", path)
+	}
+	return p, nil
+}
+
+// pickPrefab picks a prefab of at most w by h tiles for a room about to be
+// placed, or returns nil for a plain room.
+func pickPrefab(r *rand.Rand, w, h int) *Prefab {
+	if len(prefabs) == 0 || r.Intn(prefabChance) != 0 {
+		return nil
+	}
+	var fits []*Prefab
+	for _, p := range prefabs {
+		if p.W <= w && p.H <= h {
+			fits = append(fits, p)
+		}
+	}
+	if len(fits) == 0 {
+		return nil
+	}
+	return fits[r.Intn(len(fits))]
+}
+
+// stampPrefabs draws the prefabs into t, over anything carved there. A
+// corridor that ran along a prefab's edge is moved out by a tile so it
+// keeps its width, and doors no corridor leads to are walled up.
+func stampPrefabs(t [][]int, placed []PlacedPrefab) {
+	for _, pp := range placed {
+		var moved [][2]int
+		for y := 0; y < pp.Prefab.H; y++ {
+			for x := 0; x < pp.Prefab.W; x++ {
+				if t[pp.Y+y][pp.X+x] == 0 && !pp.Prefab.floor[y][x] {
+					moved = append(moved, pp.push(x, y))
+				}
+				t[pp.Y+y][pp.X+x] = 1
+				if pp.Prefab.floor[y][x] {
+					t[pp.Y+y][pp.X+x] = 0
+				}
+			}
+		}
+		for _, m := range moved {
+			if m[1] > 0 && m[1] < len(t)-1 && m[0] > 0 && m[0] < len(t[m[1]])-1 && !inPrefab(placed, m[0], m[1]) {
+				t[m[1]][m[0]] = 0
+			}
+		}
+		for _, d := range pp.Prefab.doors {
+			o := pp.outside(d)
+			if o[1] < 0 || o[1] >= len(t) || o[0] < 0 || o[0] >= len(t[o[1]]) || t[o[1]][o[0]] != 0 {
+				t[pp.Y+d[1]][pp.X+d[0]] = 1
+			}
+		}
+	}
+}
+
+// outside is the tile in front of door d, where its corridor starts.
+func (p PlacedPrefab) outside(d [2]int) [2]int {
+	x, y := p.X+d[0], p.Y+d[1]
+	switch {
+	case d[1] == 0:
+		y--
+	case d[1] == p.Prefab.H-1:
+		y++
+	case d[0] == 0:
+		x--
+	default:
+		x++
+	}
+	return [2]int{x, y}
+}
+
+// push is the tile next to the edge tile x, y of the prefab, on the
+// outside, or x, y itself for the inner tiles.
+func (p PlacedPrefab) push(x, y int) [2]int {
+	switch {
+	case x == 0:
+		x--
+	case x == p.Prefab.W-1:
+		x++
+	}
+	switch {
+	case y == 0:
+		y--
+	case y == p.Prefab.H-1:
+		y++
+	}
+	return [2]int{p.X + x, p.Y + y}
+}
+
+func inPrefab(placed []PlacedPrefab, x, y int) bool {
+	for _, pp := range placed {
+		if pp.Room().contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// roomEntry is where a corridor from rm towards to starts: the room's
+// middle, or in front of the prefab door closest to to.
+func roomEntry(rm Room, placed []PlacedPrefab, to [2]int) [2]int {
+	for _, pp := range placed {
+		if pp.Room() != rm {
+			continue
+		}
+		best, bestDist := centerOf(rm), -1
+		for _, d := range pp.Prefab.doors {
+			o := pp.outside(d)
+			if dist := abs(o[0]-to[0]) + abs(o[1]-to[1]); bestDist < 0 || dist < bestDist {
+				best, bestDist = o, dist
+			}
+		}
+		return best
+	}
+	return centerOf(rm)
+}
+
+func isPrefab(rm Room) bool {
+	for _, pp := range placedPrefabs {
+		if pp.Room() == rm {
+			return true
+		}
+	}
+	return false
+}
+
+// fillPrefabs puts the mobs and potions of the level's prefabs on their
+// spots. The mobs join the guards of the special rooms; the prefab the
+// player starts in gets none.
+func fillPrefabs(level int, spawn [2]int) {
+	r := rng.Stream("prefabs", level)
+	for _, pp := range placedPrefabs {
+		for _, m := range pp.Prefab.mobs {
+			if pp.Room().contains(spawn[0], spawn[1]) {
+				break
+			}
+			guardPositions = append(guardPositions, rl.NewVector2(float32((pp.X+m[0])*tileSize), float32((pp.Y+m[1])*tileSize)))
+		}
+		for _, p := range pp.Prefab.potions {
+			if r.Intn(prefabPotionChance) == 0 {
+				potions = append(potions, Potion{Position: rl.NewVector2(float32((pp.X+p[0])*tileSize), float32((pp.Y+p[1])*tileSize)), Active: true})
+			}
+		}
+	}
+}
+
+// decoratePrefabs adds the drawn decoration and torches of the level's
+// prefabs, which get none of the random ones.
+func decoratePrefabs() {
+	for _, pp := range placedPrefabs {
+		for _, d := range pp.Prefab.decor {
+			floorOverlays = append(floorOverlays, floorOverlay{x: pp.X + d[0], y: pp.Y + d[1], tile: d[2]})
+		}
+		for _, t := range pp.Prefab.torches {
+			x, y := pp.X+t[0], pp.Y+t[1]
+			if tiles[y][x] != 0 {
+				wallTorches = append(wallTorches, wallTorch{x: x, y: y, frame: 0, frameCount: 4})
+			}
+		}
+	}
+}
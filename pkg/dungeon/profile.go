@@ -0,0 +1,32 @@
+package dungeon
+
+// Profile is the size and shape of a generated level. Sizes are in tiles.
+type Profile struct {
+	W, H int
+	// Rooms is how many rooms the room based generators try to place, and
+	// how many open areas the others report.
+	Rooms            int
+	MinRoom, MaxRoom int
+	Corridor         int
+}
+
+// The first level is 40x25 tiles with up to 8 rooms of 4 to 8 tiles a side
+// and 2 wide corridors. Every level adds to that up to the limits below, so
+// deep levels are bigger, with more and larger rooms.
+var (
+	baseProfile = Profile{W: 40, H: 25, Rooms: 8, MinRoom: 4, MaxRoom: 8, Corridor: 2}
+	maxProfile  = Profile{W: 70, H: 40, Rooms: 14, MinRoom: 6, MaxRoom: 12, Corridor: 3}
+)
+
+// ProfileFor is the profile of a level, counted from 1.
+func ProfileFor(level int) Profile {
+	d := max(level-1, 0)
+	return Profile{
+		W:        min(baseProfile.W+2*d, maxProfile.W),
+		H:        min(baseProfile.H+d, maxProfile.H),
+		Rooms:    min(baseProfile.Rooms+d/3, maxProfile.Rooms),
+		MinRoom:  min(baseProfile.MinRoom+d/12, maxProfile.MinRoom),
+		MaxRoom:  min(baseProfile.MaxRoom+d/5, maxProfile.MaxRoom),
+		Corridor: min(baseProfile.Corridor+d/9, maxProfile.Corridor),
+	}
+}
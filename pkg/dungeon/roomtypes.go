@@ -0,0 +1,255 @@
+package dungeon
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"spooknloot/pkg/rng"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// RoomType is a kind of room defined in rooms.json. What a room does comes
+// from the fields it sets, so a type can combine them, e.g. a chest guarded
+// by an ambush.
+type RoomType struct {
+	ID string `json:"id"`
+
+	// Weight is the chance of a room getting this type on level 1;
+	// WeightPerLevel is added for every level further down. Types show up
+	// from MinLevel on and at most Max times per level (0: no limit).
+	Weight         float32 `json:"weight"`
+	WeightPerLevel float32 `json:"weightPerLevel,omitempty"`
+	MinLevel       int     `json:"minLevel,omitempty"`
+	Max            int     `json:"max,omitempty"`
+
+	// Guards are mobs waiting in the room when the level starts. Safe rooms
+	// get none of the level's other mobs.
+	Guards int  `json:"guards,omitempty"`
+	Safe   bool `json:"safe,omitempty"`
+
+	// Potions lie in the room from the start; a chest holds Chest more.
+	Potions int `json:"potions,omitempty"`
+	Chest   int `json:"chest,omitempty"`
+
+	// A room with buffs has a shrine that grants one of them.
+	Buffs []ShrineBuff `json:"buffs,omitempty"`
+
+	// Ambush mobs appear and the entrances close when the player walks in,
+	// until those mobs are dead. Only rooms with at most MaxEntrances open
+	// tiles around them can be ambushes.
+	Ambush       int `json:"ambush,omitempty"`
+	MaxEntrances int `json:"maxEntrances,omitempty"`
+
+	// Floor overlays and torches, 0 for the usual amount.
+	Overlays int `json:"overlays,omitempty"`
+	Torches  int `json:"torches,omitempty"`
+}
+
+// ShrineBuff is a buff a shrine can grant, see player.AddBuff.
+type ShrineBuff struct {
+	Kind     string  `json:"kind"`
+	Amount   float32 `json:"amount"`
+	Duration float32 `json:"duration"`
+}
+
+var roomTypes []RoomType
+
+// LoadRoomTypes reads the room types from path. Without them every room is
+// a plain room.
+func LoadRoomTypes(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var l []RoomType
+	if err := json.Unmarshal(data, &l); err != nil {
+		return err
+	}
+	for _, t := range l {
+		if t.ID == "" {
+			return fmt.Errorf("%s: room type without id", path)
+		}
+		for _, b := range t.Buffs {
+			if b.Kind == "" || b.Duration <= 0 {
+				return fmt.Errorf("%s: room type %s: buff needs a kind and a duration", path, t.ID)
+			}
+		}
+	}
+	roomTypes = l
+	return nil
+}
+
+func roomType(id string) *RoomType {
+	for i := range roomTypes {
+		if roomTypes[i].ID == id {
+			return &roomTypes[i]
+		}
+	}
+	return nil
+}
+
+// specialRoom is a room of the current level that has a type.
+type specialRoom struct {
+	room Room
+	kind *RoomType
+	// used is set once the chest is open, the shrine has been prayed at or
+	// the ambush is over.
+	used bool
+	// buff is the shrine's pick from kind.Buffs.
+	buff int
+	// sealed is set while an ambush is going on; mobs are the indices of
+	// its mobs, first to last exclusive.
+	sealed    bool
+	mobs      [2]int
+	entrances [][2]int
+}
+
+var (
+	specialRooms   []specialRoom
+	guardPositions []rl.Vector2
+)
+
+// assignRoomTypes gives the rooms of a fresh level their types from the
+// level's own stream. The rooms with the spawn and the exit stay plain.
+func assignRoomTypes(rooms []Room, level int, spawn, exit [2]int) {
+	specialRooms = specialRooms[:0]
+	guardPositions = nil
+	if len(roomTypes) == 0 {
+		return
+	}
+
+	r := rng.Stream("rooms", level)
+	counts := map[string]int{}
+	for _, rm := range rooms {
+		if rm.contains(spawn[0], spawn[1]) || rm.contains(exit[0], exit[1]) {
+			continue
+		}
+		entrances := roomEntrances(rm)
+
+		var weights []float32
+		var total float32
+		for _, t := range roomTypes {
+			w := t.Weight + t.WeightPerLevel*float32(level-1)
+			switch {
+			case level < t.MinLevel, t.Max > 0 && counts[t.ID] >= t.Max:
+				w = 0
+			case t.Ambush > 0 && (len(entrances) == 0 || t.MaxEntrances > 0 && len(entrances) > t.MaxEntrances):
+				w = 0
+			}
+			weights = append(weights, max(w, 0))
+			total += max(w, 0)
+		}
+		if total <= 0 {
+			continue
+		}
+
+		n := r.Float32() * total
+		pick := len(roomTypes) - 1
+		for i, w := range weights {
+			if n < w {
+				pick = i
+				break
+			}
+			n -= w
+		}
+		t := &roomTypes[pick]
+		counts[t.ID]++
+
+		s := specialRoom{room: rm, kind: t, entrances: entrances}
+		if len(t.Buffs) > 0 {
+			s.buff = r.Intn(len(t.Buffs))
+		}
+		specialRooms = append(specialRooms, s)
+
+		guardPositions = append(guardPositions, roomFloorPositions(r, rm, t.Guards, nil)...)
+		for _, p := range roomFloorPositions(r, rm, t.Potions, nil) {
+			potions = append(potions, Potion{Position: p, Active: true})
+		}
+	}
+}
+
+func (r Room) contains(x, y int) bool {
+	return x >= r.X && y >= r.Y && x < r.X+r.W && y < r.Y+r.H
+}
+
+// roomEntrances are the floor tiles in the ring around rm, where corridors
+// and neighbouring caves lead in, or the open doors of a prefab.
+func roomEntrances(rm Room) [][2]int {
+	var out [][2]int
+	for _, pp := range placedPrefabs {
+		if pp.Room() != rm {
+			continue
+		}
+		for _, d := range pp.Prefab.doors {
+			if x, y := pp.X+d[0], pp.Y+d[1]; tiles[y][x] == 0 {
+				out = append(out, [2]int{x, y})
+			}
+		}
+		return out
+	}
+	for y := rm.Y - 1; y <= rm.Y+rm.H; y++ {
+		for x := rm.X - 1; x <= rm.X+rm.W; x++ {
+			if rm.contains(x, y) || y < 0 || y >= len(tiles) || x < 0 || x >= len(tiles[y]) {
+				continue
+			}
+			if tiles[y][x] == 0 {
+				out = append(out, [2]int{x, y})
+			}
+		}
+	}
+	return out
+}
+
+// roomFloorPositions picks n distinct floor tiles (in pixels) inside rm,
+// leaving out the center where chests and shrines stand and any tile skip
+// reports.
+func roomFloorPositions(r *rand.Rand, rm Room, n int, skip func(x, y int) bool) []rl.Vector2 {
+	if n <= 0 {
+		return nil
+	}
+	cx, cy := rm.Center()
+	var floor []rl.Vector2
+	for y := rm.Y; y < rm.Y+rm.H; y++ {
+		for x := rm.X; x < rm.X+rm.W; x++ {
+			if tiles[y][x] != 0 || (x == cx && y == cy) || (skip != nil && skip(x, y)) {
+				continue
+			}
+			floor = append(floor, rl.NewVector2(float32(x*tileSize), float32(y*tileSize)))
+		}
+	}
+	r.Shuffle(len(floor), func(i, j int) { floor[i], floor[j] = floor[j], floor[i] })
+	return floor[:min(n, len(floor))]
+}
+
+// typeOf is the type of rm, nil for plain rooms.
+func typeOf(rm Room) *RoomType {
+	for _, s := range specialRooms {
+		if s.room == rm {
+			return s.kind
+		}
+	}
+	return nil
+}
+
+// noSpawn reports whether the level's random mobs must stay out of the
+// tile: safe rooms, ambushes that haven't been sprung and locked doors.
+func noSpawn(x, y int) bool {
+	if lockTile(x, y) {
+		return true
+	}
+	for _, s := range specialRooms {
+		if (s.kind.Safe || s.kind.Ambush > 0) && s.room.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// GuardPositions are where the guards of the level's special rooms stand
+// when it starts.
+func GuardPositions() []rl.Vector2 {
+	return guardPositions
+}
@@ -0,0 +1,153 @@
+package dungeon
+
+import (
+	"math"
+
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// UpdateRooms runs the special rooms: chests and shrines open when the
+// player touches them, ambushes close when the player walks in and open
+// again once their mobs are dead. It reports whether the colliders changed,
+// in which case GetColliders has to be handed out again.
+func UpdateRooms(playerHitbox rl.Rectangle) bool {
+	changed := false
+	for i := range specialRooms {
+		s := &specialRooms[i]
+		t := s.kind
+
+		if !s.used && (t.Chest > 0 || len(t.Buffs) > 0) && rl.CheckCollisionRecs(playerHitbox, centerRect(s.room)) {
+			s.used = true
+			if t.Chest > 0 {
+				for _, p := range roomFloorPositions(potionRand, s.room, t.Chest, nil) {
+					potions = append(potions, Potion{Position: p, Active: true})
+				}
+				audio.RestartSound("door")
+			}
+			if len(t.Buffs) > 0 {
+				b := t.Buffs[s.buff]
+				player.AddBuff(b.Kind, b.Amount, b.Duration)
+				audio.RestartSound("drink")
+			}
+		}
+
+		if t.Ambush == 0 || s.used {
+			continue
+		}
+		if !s.sealed && insideRoom(playerHitbox, s.room) {
+			s.sealed = true
+			// Keep the new mobs off the player's back
+			px, py := int(playerHitbox.X)/tileSize, int(playerHitbox.Y)/tileSize
+			near := func(x, y int) bool { return abs(x-px) <= 1 && abs(y-py) <= 1 }
+			s.mobs[0] = mobs.MobCount()
+			s.mobs[1] = mobs.SpawnMobsAtPositions(roomFloorPositions(spawnRand, s.room, t.Ambush, near), "random")
+			audio.RestartSound("door")
+			changed = true
+		} else if s.sealed && !ambushAlive(s) {
+			s.sealed = false
+			s.used = true
+			audio.RestartSound("door")
+			changed = true
+		}
+	}
+	if changed {
+		buildColliders()
+	}
+	return changed
+}
+
+func ambushAlive(s *specialRoom) bool {
+	for i := s.mobs[0]; i < s.mobs[1]; i++ {
+		if mobs.IsMobAliveByIndex(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func centerRect(rm Room) rl.Rectangle {
+	x, y := rm.Center()
+	return rl.NewRectangle(float32(x*tileSize), float32(y*tileSize), tileSize, tileSize)
+}
+
+// insideRoom reports whether the hitbox is fully inside rm, past its
+// entrances.
+func insideRoom(hitbox rl.Rectangle, rm Room) bool {
+	return hitbox.X >= float32(rm.X*tileSize) && hitbox.Y >= float32(rm.Y*tileSize) &&
+		hitbox.X+hitbox.Width <= float32((rm.X+rm.W)*tileSize) &&
+		hitbox.Y+hitbox.Height <= float32((rm.Y+rm.H)*tileSize)
+}
+
+// sealedColliders are the closed entrances of running ambushes.
+func sealedColliders() []rl.Rectangle {
+	var out []rl.Rectangle
+	for _, s := range specialRooms {
+		if !s.sealed {
+			continue
+		}
+		for _, e := range s.entrances {
+			out = append(out, rl.NewRectangle(float32(e[0]*tileSize), float32(e[1]*tileSize), tileSize, tileSize))
+		}
+	}
+	return out
+}
+
+var (
+	chestColor     = rl.NewColor(122, 74, 42, 255)
+	chestLidColor  = rl.NewColor(92, 52, 30, 255)
+	chestGold      = rl.NewColor(230, 190, 80, 255)
+	shrineStone    = rl.NewColor(110, 104, 120, 255)
+	gateColor      = rl.NewColor(41, 29, 43, 255)
+	gateBarColor   = rl.NewColor(134, 87, 87, 255)
+	buffOrbColors  = map[string]rl.Color{"damage": rl.NewColor(220, 70, 60, 255), "speed": rl.NewColor(90, 200, 230, 255), "regen": rl.NewColor(110, 210, 110, 255)}
+	spentOrbColor  = rl.NewColor(80, 76, 88, 255)
+	openChestColor = rl.NewColor(30, 20, 16, 255)
+)
+
+func drawSpecialRooms() {
+	for _, s := range specialRooms {
+		c := centerRect(s.room)
+		x, y := int32(c.X), int32(c.Y)
+
+		if s.kind.Chest > 0 {
+			if s.used {
+				rl.DrawRectangle(x+2, y+6, 12, 8, chestColor)
+				rl.DrawRectangle(x+3, y+6, 10, 3, openChestColor)
+				rl.DrawRectangle(x+2, y+2, 12, 3, chestLidColor)
+			} else {
+				rl.DrawRectangle(x+2, y+6, 12, 8, chestColor)
+				rl.DrawRectangle(x+2, y+4, 12, 4, chestLidColor)
+				rl.DrawRectangle(x+7, y+7, 2, 3, chestGold)
+			}
+		}
+
+		if len(s.kind.Buffs) > 0 {
+			rl.DrawRectangle(x+3, y+11, 10, 4, shrineStone)
+			rl.DrawRectangle(x+5, y+5, 6, 7, shrineStone)
+			orb := spentOrbColor
+			if !s.used {
+				orb = buffOrbColors[s.kind.Buffs[s.buff].Kind]
+				if orb.A == 0 {
+					orb = chestGold
+				}
+				pulse := 0.7 + 0.3*float32(math.Sin(rl.GetTime()*3))
+				rl.DrawCircle(x+8, y+4, 5, rl.Fade(orb, 0.3*pulse))
+			}
+			rl.DrawCircle(x+8, y+4, 3, orb)
+		}
+
+		if s.sealed {
+			for _, e := range s.entrances {
+				ex, ey := int32(e[0]*tileSize), int32(e[1]*tileSize)
+				rl.DrawRectangle(ex, ey, tileSize, tileSize, gateColor)
+				for bx := int32(2); bx < tileSize; bx += 5 {
+					rl.DrawRectangle(ex+bx, ey, 2, tileSize, gateBarColor)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package dungeon
+
+import (
+	"spooknloot/pkg/rng"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// State is the generated level as it currently stands, including what the
+// player already picked up.
+type State struct {
+	Tiles       [][]int
+	Spawn       rl.Vector2
+	Exit        rl.Rectangle
+	ExitVisible bool
+	Overlays    [][3]int // x, y, tile
+	Torches     [][2]int // x, y
+	Potions     []rl.Vector2
+	Rooms       []RoomState `json:",omitempty"`
+	Traps       []TrapState `json:",omitempty"`
+	Locks       []LockState `json:",omitempty"`
+
+	PotionRand *rng.StreamState `json:",omitempty"`
+	SpawnRand  *rng.StreamState `json:",omitempty"`
+}
+
+// RoomState is a special room and how far the player got with it.
+type RoomState struct {
+	Room   Room
+	Type   string
+	Used   bool `json:",omitempty"`
+	Buff   int  `json:",omitempty"`
+	Sealed bool `json:",omitempty"`
+	Mobs   [2]int
+	// Entrances are saved since those of a prefab can't be found again
+	Entrances [][2]int `json:",omitempty"`
+}
+
+// TrapState is a trap tile; what it is doing right now isn't kept.
+type TrapState struct {
+	X, Y     int
+	Kind     TrapKind
+	Phase    float32 `json:",omitempty"`
+	Launcher [2]int  `json:",omitempty"`
+	Dir      [2]int  `json:",omitempty"`
+}
+
+// LockState is a locked room, its key or lever and how far the player got
+// with them.
+type LockState struct {
+	Doors   [][2]int
+	Kind    LockKind
+	At      [2]int
+	Holder  int  `json:",omitempty"`
+	Used    bool `json:",omitempty"`
+	Dropped bool `json:",omitempty"`
+	Held    bool `json:",omitempty"`
+	Open    bool `json:",omitempty"`
+}
+
+func Snapshot() State {
+	s := State{
+		Tiles:       make([][]int, len(tiles)),
+		Spawn:       spawnPx,
+		Exit:        exitPx,
+		ExitVisible: exitVisible,
+	}
+	for y := range tiles {
+		s.Tiles[y] = append([]int(nil), tiles[y]...)
+	}
+	for _, ov := range floorOverlays {
+		s.Overlays = append(s.Overlays, [3]int{ov.x, ov.y, ov.tile})
+	}
+	for _, t := range wallTorches {
+		s.Torches = append(s.Torches, [2]int{t.x, t.y})
+	}
+	for _, p := range potions {
+		if p.Active {
+			s.Potions = append(s.Potions, p.Position)
+		}
+	}
+	for _, r := range specialRooms {
+		s.Rooms = append(s.Rooms, RoomState{Room: r.room, Type: r.kind.ID, Used: r.used, Buff: r.buff, Sealed: r.sealed, Mobs: r.mobs, Entrances: r.entrances})
+	}
+	for _, t := range traps {
+		s.Traps = append(s.Traps, TrapState{X: t.x, Y: t.y, Kind: t.kind, Phase: t.phase, Launcher: t.launcher, Dir: t.dir})
+	}
+	for _, l := range locks {
+		s.Locks = append(s.Locks, LockState{Doors: l.doors, Kind: l.kind, At: l.at, Holder: l.holder, Used: l.used, Dropped: l.dropped, Held: l.held, Open: l.open})
+	}
+	if r, ok := rng.State(potionRand); ok {
+		s.PotionRand = &r
+	}
+	if r, ok := rng.State(spawnRand); ok {
+		s.SpawnRand = &r
+	}
+	return s
+}
+
+// Restore rebuilds a level saved with Snapshot without generating anything.
+func Restore(s State) {
+	tiles = make([][]int, len(s.Tiles))
+	for y := range s.Tiles {
+		tiles[y] = append([]int(nil), s.Tiles[y]...)
+	}
+	spawnPx = s.Spawn
+	exitPx = s.Exit
+	exitVisible = s.ExitVisible
+
+	specialRooms = specialRooms[:0]
+	guardPositions = nil
+	placedPrefabs = nil
+	for _, r := range s.Rooms {
+		t := roomType(r.Type)
+		if t == nil || (len(t.Buffs) > 0 && r.Buff >= len(t.Buffs)) {
+			continue
+		}
+		entrances := r.Entrances
+		if entrances == nil {
+			entrances = roomEntrances(r.Room)
+		}
+		specialRooms = append(specialRooms, specialRoom{
+			room:      r.Room,
+			kind:      t,
+			used:      r.Used,
+			buff:      r.Buff,
+			sealed:    r.Sealed,
+			mobs:      r.Mobs,
+			entrances: entrances,
+		})
+	}
+	resetLocks()
+	for _, l := range s.Locks {
+		locks = append(locks, lock{doors: l.Doors, kind: l.Kind, at: l.At, holder: l.Holder, used: l.Used, dropped: l.Dropped, held: l.Held, open: l.Open})
+	}
+	buildColliders()
+
+	floorOverlays = floorOverlays[:0]
+	for _, ov := range s.Overlays {
+		floorOverlays = append(floorOverlays, floorOverlay{x: ov[0], y: ov[1], tile: ov[2]})
+	}
+	wallTorches = wallTorches[:0]
+	for _, t := range s.Torches {
+		wallTorches = append(wallTorches, wallTorch{x: t[0], y: t[1], frame: 0, frameCount: 4})
+	}
+	resetPotion()
+	for _, p := range s.Potions {
+		potions = append(potions, Potion{Position: p, Active: true})
+	}
+	resetTraps()
+	for _, t := range s.Traps {
+		traps = append(traps, trap{x: t.X, y: t.Y, kind: t.Kind, phase: t.Phase, launcher: t.Launcher, dir: t.Dir})
+	}
+
+	if s.PotionRand != nil {
+		potionRand = rng.Resume(*s.PotionRand)
+	}
+	if s.SpawnRand != nil {
+		spawnRand = rng.Resume(*s.SpawnRand)
+	}
+}
@@ -0,0 +1,596 @@
+package dungeon
+
+import (
+	"math"
+	"math/rand"
+
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// TrapKind is a floor tile that hurts whoever stands on it at the wrong
+// time, the player and mobs alike.
+type TrapKind int
+
+const (
+	// TrapSpikes come out of the floor every few seconds. They are laid
+	// across corridors.
+	TrapSpikes TrapKind = iota + 1
+	// TrapPlate fires an arrow from a launcher in the wall when stepped on.
+	TrapPlate
+	// TrapVent puffs poison into the tiles around it now and then.
+	TrapVent
+)
+
+const (
+	trapMinLevel   = 2
+	maxTraps       = 12
+	trapSpawnSpace = 5 // tiles walked from spawn before the first trap
+
+	spikeCycle  = 2.5
+	spikeOut    = 0.8 // the last part of a cycle the spikes are out
+	spikeWarn   = 0.4 // the tips show this long before
+	spikeDamage = 1.5
+
+	plateRearm      = 1.0
+	plateMinReach   = 2 // tiles between plate and launcher
+	plateMaxReach   = 8
+	arrowSpeed      = 160
+	arrowDamage     = 2.5
+	arrowHitboxSize = 4
+
+	ventCycle    = 5.0
+	ventPuff     = 2.0
+	ventWarn     = 0.6
+	ventRadius   = 1.5 * tileSize
+	poisonTick   = 0.5
+	poisonDamage = 0.25
+)
+
+type trap struct {
+	x, y int
+	kind TrapKind
+	// phase shifts the spike and vent cycles so traps don't all fire
+	// together; the tiles of one spike strip share it.
+	phase float32
+
+	// launcher is the wall tile a plate fires from and dir the way the
+	// arrow flies.
+	launcher [2]int
+	dir      [2]int
+	pressed  bool
+	rearm    float32
+
+	// hit are the targets spikes already hurt this cycle, playerTarget for
+	// the player.
+	hit map[int]bool
+	// tick counts down to the next poison damage.
+	tick float32
+}
+
+type arrow struct {
+	pos rl.Vector2
+	dir [2]int
+}
+
+const playerTarget = -1
+
+var (
+	traps        []trap
+	arrows       []arrow
+	trapTime     float32
+	trapsTexture rl.Texture2D
+
+	// trapStrength scales the damage traps deal to the player, set per
+	// level by SetTrapStrength.
+	trapStrength float32 = 1
+)
+
+// SetTrapStrength scales the damage traps deal to the player the way mob
+// hits are scaled: by difficulty, run strength and armor.
+func SetTrapStrength(f float32) {
+	trapStrength = f
+}
+
+// Sprite rows in traps.png.
+const (
+	trapRowSpikes = 0
+	trapRowPlate  = 1
+	trapRowVent   = 2
+	trapRowCloud  = 3
+)
+
+func initTraps() {
+	trapsTexture = backend.Current.LoadTexture("assets/dungeon/traps.png")
+	backend.Current.SetTextureFilter(trapsTexture, rl.FilterPoint)
+}
+
+func unloadTraps() {
+	if trapsTexture.ID != 0 {
+		backend.Current.UnloadTexture(trapsTexture)
+		trapsTexture = rl.Texture2D{}
+	}
+	resetTraps()
+}
+
+func resetTraps() {
+	traps = nil
+	arrows = nil
+	trapTime = 0
+}
+
+// trapCount is how many traps a level gets; a spike strip counts once.
+func trapCount(level int) int {
+	if level < trapMinLevel {
+		return 0
+	}
+	return min((level+1)/2, maxTraps)
+}
+
+// placeTraps lays the traps of a fresh level from its own stream: spikes
+// across corridors, plates and vents inside rooms. None lie near the spawn
+// or the exit, in safe rooms, under potions or where chests and shrines
+// stand.
+func placeTraps(rooms []Room, level int, spawn, exit [2]int) {
+	resetTraps()
+	n := trapCount(level)
+	if n == 0 {
+		return
+	}
+
+	r := rng.Stream("traps", level)
+	dist := floorDistances(tiles, spawn[0], spawn[1])
+	taken := map[[2]int]bool{}
+	for _, p := range potions {
+		taken[[2]int{int(p.Position.X) / tileSize, int(p.Position.Y) / tileSize}] = true
+	}
+	inRoom := func(x, y int) bool {
+		for _, rm := range rooms {
+			if rm.contains(x, y) {
+				return true
+			}
+		}
+		return false
+	}
+	free := func(x, y int) bool {
+		if tiles[y][x] != 0 || dist[y][x] < trapSpawnSpace || noTrap(x, y) {
+			return false
+		}
+		if abs(x-exit[0]) <= 2 && abs(y-exit[1]) <= 2 {
+			return false
+		}
+		// Keep a tile between traps
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if taken[[2]int{x + dx, y + dy}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	var corridor, room [][2]int
+	mapW, mapH := mapSize()
+	for y := 1; y < mapH-1; y++ {
+		for x := 1; x < mapW-1; x++ {
+			if tiles[y][x] != 0 {
+				continue
+			}
+			if inRoom(x, y) {
+				room = append(room, [2]int{x, y})
+			} else {
+				corridor = append(corridor, [2]int{x, y})
+			}
+		}
+	}
+	shuffle := func(l [][2]int) {
+		r.Shuffle(len(l), func(i, j int) { l[i], l[j] = l[j], l[i] })
+	}
+	shuffle(corridor)
+	shuffle(room)
+
+	place := map[TrapKind]func() bool{
+		TrapSpikes: func() bool {
+			for _, c := range corridor {
+				strip := spikeStrip(c[0], c[1], inRoom)
+				ok := len(strip) > 0
+				for _, t := range strip {
+					ok = ok && free(t[0], t[1])
+				}
+				if !ok {
+					continue
+				}
+				phase := r.Float32() * spikeCycle
+				for _, t := range strip {
+					traps = append(traps, trap{x: t[0], y: t[1], kind: TrapSpikes, phase: phase})
+					taken[t] = true
+				}
+				return true
+			}
+			return false
+		},
+		TrapPlate: func() bool {
+			for _, c := range room {
+				if !free(c[0], c[1]) {
+					continue
+				}
+				launcher, dir, ok := launcherFor(r, c[0], c[1])
+				if !ok {
+					continue
+				}
+				traps = append(traps, trap{x: c[0], y: c[1], kind: TrapPlate, launcher: launcher, dir: dir})
+				taken[c] = true
+				return true
+			}
+			return false
+		},
+		TrapVent: func() bool {
+			for _, c := range room {
+				if !free(c[0], c[1]) {
+					continue
+				}
+				traps = append(traps, trap{x: c[0], y: c[1], kind: TrapVent, phase: r.Float32() * ventCycle})
+				taken[c] = true
+				return true
+			}
+			return false
+		},
+	}
+
+	kinds := []TrapKind{TrapSpikes}
+	if level >= 3 {
+		kinds = append(kinds, TrapPlate)
+	}
+	if level >= 4 {
+		kinds = append(kinds, TrapVent)
+	}
+	for i := 0; i < n; i++ {
+		// Fall back on the other kinds when the level has no room for the
+		// one picked
+		first := r.Intn(len(kinds))
+		for k := range kinds {
+			if place[kinds[(first+k)%len(kinds)]]() {
+				break
+			}
+		}
+	}
+}
+
+// spikeStrip is the row of tiles across the corridor at x, y, or nil if
+// the tile isn't in a straight corridor.
+func spikeStrip(x, y int, inRoom func(x, y int) bool) [][2]int {
+	run := func(dx, dy int) int {
+		n := 0
+		for n < 4 {
+			nx, ny := x+dx*(n+1), y+dy*(n+1)
+			if ny < 0 || ny >= len(tiles) || nx < 0 || nx >= len(tiles[ny]) || tiles[ny][nx] != 0 || inRoom(nx, ny) {
+				break
+			}
+			n++
+		}
+		return n
+	}
+	left, right, up, down := run(-1, 0), run(1, 0), run(0, -1), run(0, 1)
+
+	var strip [][2]int
+	switch {
+	case left+right+1 <= 3 && up+down+1 > 3:
+		for sx := x - left; sx <= x+right; sx++ {
+			strip = append(strip, [2]int{sx, y})
+		}
+	case up+down+1 <= 3 && left+right+1 > 3:
+		for sy := y - up; sy <= y+down; sy++ {
+			strip = append(strip, [2]int{x, sy})
+		}
+	}
+	// Keep strips out of room doorways
+	for _, t := range strip {
+		for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nx, ny := t[0]+d[0], t[1]+d[1]
+			if ny >= 0 && ny < len(tiles) && nx >= 0 && nx < len(tiles[ny]) && tiles[ny][nx] == 0 && inRoom(nx, ny) {
+				return nil
+			}
+		}
+	}
+	return strip
+}
+
+// launcherFor finds a straight wall in line with a plate at x, y, with
+// open floor in between, and the direction an arrow from it flies.
+func launcherFor(r *rand.Rand, x, y int) ([2]int, [2]int, bool) {
+	dirs := [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+	r.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
+	for _, d := range dirs {
+		for n := 1; n <= plateMaxReach+1; n++ {
+			wx, wy := x+d[0]*n, y+d[1]*n
+			if wy < 0 || wy >= len(tiles) || wx < 0 || wx >= len(tiles[wy]) {
+				break
+			}
+			t := tiles[wy][wx]
+			if t == 0 {
+				continue
+			}
+			// Only the straight walls 1-4 have a face to shoot from
+			if n > plateMinReach && t >= 1 && t <= 4 {
+				return [2]int{wx, wy}, [2]int{-d[0], -d[1]}, true
+			}
+			break
+		}
+	}
+	return [2]int{}, [2]int{}, false
+}
+
+// noTrap reports whether traps have to keep off the tile: safe rooms, the
+// middle of special rooms, where chests and shrines stand, and the doors,
+// chests and levers of locks.
+func noTrap(x, y int) bool {
+	if lockTile(x, y) {
+		return true
+	}
+	for _, s := range specialRooms {
+		cx, cy := s.room.Center()
+		if (s.kind.Safe && s.room.contains(x, y)) || (x == cx && y == cy) {
+			return true
+		}
+	}
+	return false
+}
+
+func spikesOut(t trap) bool {
+	return cyclePos(t.phase, spikeCycle) >= spikeCycle-spikeOut
+}
+
+func venting(t trap) bool {
+	return cyclePos(t.phase, ventCycle) >= ventCycle-ventPuff
+}
+
+func cyclePos(phase, cycle float32) float32 {
+	return float32(math.Mod(float64(trapTime+phase), float64(cycle)))
+}
+
+func tileRect(x, y int) rl.Rectangle {
+	return rl.NewRectangle(float32(x*tileSize), float32(y*tileSize), tileSize, tileSize)
+}
+
+// trapTarget is someone traps can hurt.
+type trapTarget struct {
+	id     int
+	hitbox rl.Rectangle
+	flying bool
+}
+
+func trapTargets(playerHitbox rl.Rectangle) []trapTarget {
+	var out []trapTarget
+	if !player.IsPlayerDead() {
+		out = append(out, trapTarget{id: playerTarget, hitbox: playerHitbox})
+	}
+	for i := 0; i < mobs.MobCount(); i++ {
+		if mobs.IsMobAliveByIndex(i) {
+			out = append(out, trapTarget{id: i, hitbox: mobs.GetMobHitboxByIndex(i), flying: mobs.IsMobFlyingByIndex(i)})
+		}
+	}
+	return out
+}
+
+func hurt(id int, damage float32) {
+	if id == playerTarget {
+		player.SetPlayerDamageState()
+		player.TakeDamage(damage * trapStrength)
+		return
+	}
+	mobs.HurtMob(id, damage)
+}
+
+// UpdateTraps runs the level's traps against the player and the mobs.
+// Bats fly over spikes and plates but not through arrows and poison.
+func UpdateTraps(playerHitbox rl.Rectangle, dt float32) {
+	if len(traps) == 0 {
+		return
+	}
+	trapTime += dt
+	targets := trapTargets(playerHitbox)
+
+	for i := range traps {
+		t := &traps[i]
+		rect := tileRect(t.x, t.y)
+
+		switch t.kind {
+		case TrapSpikes:
+			if !spikesOut(*t) {
+				t.hit = nil
+				continue
+			}
+			for _, tg := range targets {
+				if tg.flying || t.hit[tg.id] || !rl.CheckCollisionRecs(tg.hitbox, rect) {
+					continue
+				}
+				if t.hit == nil {
+					t.hit = map[int]bool{}
+				}
+				t.hit[tg.id] = true
+				hurt(tg.id, spikeDamage)
+			}
+
+		case TrapPlate:
+			t.rearm -= dt
+			pressed := false
+			for _, tg := range targets {
+				if !tg.flying && rl.CheckCollisionRecs(tg.hitbox, rect) {
+					pressed = true
+					break
+				}
+			}
+			if pressed && !t.pressed && t.rearm <= 0 {
+				start := rl.NewVector2(float32(t.launcher[0]*tileSize+tileSize/2+t.dir[0]*tileSize/2), float32(t.launcher[1]*tileSize+tileSize/2+t.dir[1]*tileSize/2))
+				arrows = append(arrows, arrow{pos: start, dir: t.dir})
+				t.rearm = plateRearm
+				audio.RestartSound("attack")
+			}
+			t.pressed = pressed
+
+		case TrapVent:
+			if !venting(*t) {
+				t.tick = 0
+				continue
+			}
+			t.tick -= dt
+			if t.tick > 0 {
+				continue
+			}
+			t.tick = poisonTick
+			center := rl.NewVector2(rect.X+tileSize/2, rect.Y+tileSize/2)
+			for _, tg := range targets {
+				c := rl.NewVector2(tg.hitbox.X+tg.hitbox.Width/2, tg.hitbox.Y+tg.hitbox.Height/2)
+				if rl.Vector2Distance(center, c) <= ventRadius {
+					hurt(tg.id, poisonDamage)
+				}
+			}
+		}
+	}
+
+	updateArrows(targets, dt)
+}
+
+func updateArrows(targets []trapTarget, dt float32) {
+	// Closed doors stop arrows like walls do
+	doors := append(lockedColliders(), sealedColliders()...)
+	kept := arrows[:0]
+	for _, a := range arrows {
+		a.pos.X += float32(a.dir[0]) * arrowSpeed * dt
+		a.pos.Y += float32(a.dir[1]) * arrowSpeed * dt
+
+		tx, ty := int(a.pos.X)/tileSize, int(a.pos.Y)/tileSize
+		if a.pos.X < 0 || a.pos.Y < 0 || ty >= len(tiles) || tx >= len(tiles[ty]) || (tiles[ty][tx] != 0 && tiles[ty][tx] != 9) {
+			continue
+		}
+
+		box := rl.NewRectangle(a.pos.X-arrowHitboxSize/2, a.pos.Y-arrowHitboxSize/2, arrowHitboxSize, arrowHitboxSize)
+		if collidesAny(box, doors) {
+			continue
+		}
+		hit := false
+		for _, tg := range targets {
+			if rl.CheckCollisionRecs(tg.hitbox, box) {
+				hurt(tg.id, arrowDamage)
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			kept = append(kept, a)
+		}
+	}
+	arrows = kept
+}
+
+func collidesAny(box rl.Rectangle, rects []rl.Rectangle) bool {
+	for _, r := range rects {
+		if rl.CheckCollisionRecs(r, box) {
+			return true
+		}
+	}
+	return false
+}
+
+func trapSrc(col, row int) rl.Rectangle {
+	return rl.NewRectangle(float32(col*tileSize), float32(row*tileSize), tileSize, tileSize)
+}
+
+// dirAngle turns a direction into the rotation of a sprite drawn facing
+// right.
+func dirAngle(d [2]int) float32 {
+	switch {
+	case d[1] > 0:
+		return 90
+	case d[0] < 0:
+		return 180
+	case d[1] < 0:
+		return 270
+	}
+	return 0
+}
+
+// drawTraps draws the trap tiles and launchers under everyone walking
+// over them.
+func drawTraps() {
+	if trapsTexture.ID == 0 {
+		return
+	}
+	half := rl.NewVector2(tileSize/2, tileSize/2)
+	for _, t := range traps {
+		dest := tileRect(t.x, t.y)
+		switch t.kind {
+		case TrapSpikes:
+			p := cyclePos(t.phase, spikeCycle)
+			frame := 0
+			switch {
+			case p >= spikeCycle-spikeOut+0.08:
+				frame = 3
+			case p >= spikeCycle-spikeOut:
+				frame = 2
+			case p >= spikeCycle-spikeOut-spikeWarn:
+				frame = 1
+			}
+			rl.DrawTexturePro(trapsTexture, trapSrc(frame, trapRowSpikes), dest, rl.Vector2{}, 0, rl.White)
+
+		case TrapPlate:
+			frame := 0
+			if t.pressed {
+				frame = 1
+			}
+			rl.DrawTexturePro(trapsTexture, trapSrc(frame, trapRowPlate), dest, rl.Vector2{}, 0, rl.White)
+
+			// The slot faces the way it shoots
+			l := tileRect(t.launcher[0], t.launcher[1])
+			l.X += half.X
+			l.Y += half.Y
+			rl.DrawTexturePro(trapsTexture, trapSrc(2, trapRowPlate), l, half, dirAngle(t.dir)-90, rl.White)
+
+		case TrapVent:
+			p := cyclePos(t.phase, ventCycle)
+			frame := 0
+			switch {
+			case p >= ventCycle-ventPuff:
+				frame = 2 + int(trapTime*8)%2
+			case p >= ventCycle-ventPuff-ventWarn:
+				frame = 1
+			}
+			rl.DrawTexturePro(trapsTexture, trapSrc(frame, trapRowVent), dest, rl.Vector2{}, 0, rl.White)
+		}
+	}
+}
+
+// DrawTrapEffects draws flying arrows and poison clouds. It goes on top of
+// the mobs and the player.
+func DrawTrapEffects() {
+	if trapsTexture.ID == 0 {
+		return
+	}
+	for _, t := range traps {
+		if t.kind != TrapVent || !venting(t) {
+			continue
+		}
+		// The cloud swells over the puff and fades at its end
+		p := (cyclePos(t.phase, ventCycle) - (ventCycle - ventPuff)) / ventPuff
+		frame := min(int(p*4), 3)
+		size := float32(2 * ventRadius)
+		dest := rl.NewRectangle(float32(t.x*tileSize)+tileSize/2-size/2, float32(t.y*tileSize)+tileSize/2-size/2, size, size)
+		alpha := float32(1)
+		if p > 0.75 {
+			alpha = (1 - p) * 4
+		}
+		rl.DrawTexturePro(trapsTexture, trapSrc(frame, trapRowCloud), dest, rl.Vector2{}, 0, rl.Fade(rl.White, alpha))
+	}
+
+	half := rl.NewVector2(tileSize/2, tileSize/2)
+	for _, a := range arrows {
+		dest := rl.NewRectangle(a.pos.X, a.pos.Y, tileSize, tileSize)
+		rl.DrawTexturePro(trapsTexture, trapSrc(3, trapRowPlate), dest, half, dirAngle(a.dir), rl.White)
+	}
+}
@@ -1,8 +1,6 @@
 package dungeon
 
 import (
-	"math/rand"
-
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -24,6 +22,7 @@ func generateRoomWallTorches(rooms []Room) {
 	}
 
 	// Helper to check floor
+	mapW, mapH := mapSize()
 	isFloor := func(x, y int) bool {
 		if x < 0 || y < 0 || x >= mapW || y >= mapH {
 			return false
@@ -32,6 +31,9 @@ func generateRoomWallTorches(rooms []Room) {
 	}
 
 	for _, r := range rooms {
+		if isPrefab(r) {
+			continue
+		}
 		candidates := make([]wallTorch, 0, r.W)
 
 		// Top walls in this room: tile index 1 and floor directly below → front torch (frame 0)
@@ -46,7 +48,7 @@ func generateRoomWallTorches(rooms []Room) {
 			continue
 		}
 
-		rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		decorRand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
 		maxPerRoom := 3
 		if maxPerRoom > len(candidates) {
 			maxPerRoom = len(candidates)
@@ -55,7 +57,10 @@ func generateRoomWallTorches(rooms []Room) {
 			continue
 		}
 		// Place only a few torches per room (1..maxPerRoom)
-		count := rand.Intn(maxPerRoom) + 1
+		count := decorRand.Intn(maxPerRoom) + 1
+		if t := typeOf(r); t != nil && t.Torches > 0 {
+			count = min(t.Torches, len(candidates))
+		}
 		wallTorches = append(wallTorches, candidates[:count]...)
 	}
 }
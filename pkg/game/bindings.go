@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+	"os"
+
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/ui"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// bindingsScene lists every action with its keys. Confirm on a row waits
+// for the next key or mouse button and binds it; Escape cancels.
+type bindingsScene struct {
+	selected int
+	waiting  bool
+}
+
+func newBindingsScene() *bindingsScene {
+	return &bindingsScene{}
+}
+
+func (s *bindingsScene) IsOverlay() bool { return true }
+
+func (s *bindingsScene) Enter() {}
+
+func (s *bindingsScene) Exit() {}
+
+func (s *bindingsScene) HandleInput() {
+	action := input.Actions[s.selected]
+
+	if s.waiting {
+		b, ok := input.AnyPressed()
+		if !ok {
+			return
+		}
+		s.waiting = false
+		if b == input.Key(rl.KeyEscape) {
+			return
+		}
+		if err := input.Rebind(action, b); err != nil {
+			fmt.Fprintln(os.Stderr, "saving key bindings:", err)
+		}
+		return
+	}
+
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+	if input.Pressed(input.ResetBinding) {
+		if err := input.Reset(action); err != nil {
+			fmt.Fprintln(os.Stderr, "saving key bindings:", err)
+		}
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, len(input.Actions))
+	if input.Pressed(input.Confirm) {
+		s.waiting = true
+	}
+	if i := clickedRect(ui.GetBindingRowRects()); i >= 0 {
+		s.selected = i
+		s.waiting = true
+	}
+}
+
+func (s *bindingsScene) Update(dt float32) {}
+
+func (s *bindingsScene) Draw() {
+	rows := make([]ui.BindingRow, len(input.Actions))
+	for i, a := range input.Actions {
+		rows[i] = ui.BindingRow{Label: input.Label(a), Keys: input.Describe(a)}
+	}
+	hint := i18n.T("bindings.hint", input.Describe(input.Confirm), input.Describe(input.ResetBinding), input.Describe(input.Pause))
+	if s.waiting {
+		hint = i18n.T("bindings.waiting")
+	}
+	ui.DrawBindingsScreen(rows, s.selected, s.waiting, hint)
+}
+
+// capturingInput reports whether the rebinding screen is waiting for a key,
+// in which case the global hotkeys must not react to it.
+func capturingInput() bool {
+	b, ok := scenes.Current().(*bindingsScene)
+	return ok && b.waiting
+}
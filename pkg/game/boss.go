@@ -0,0 +1,141 @@
+package game
+
+import (
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/boss"
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/save"
+	"spooknloot/pkg/stats"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// bossLevel numbers the arena after the last dungeon level for the RNG streams.
+const bossLevel = dungeonLevels + 1
+
+// bossScene is the final arena reached after clearing every dungeon level.
+// In endless mode the boss returns every endlessBossEvery levels and the
+// run goes on after it dies.
+type bossScene struct {
+	resume *save.Run
+}
+
+func newBossScene() *bossScene {
+	return &bossScene{}
+}
+
+func (s *bossScene) Enter() {
+	applyStrength()
+	player.SetExternalColliders(boss.GetColliders())
+	mobs.SetExternalColliders(boss.GetColliders())
+
+	if s.resume != nil {
+		if s.resume.Dungeon != nil {
+			dungeon.Restore(*s.resume.Dungeon)
+		}
+		mobs.Restore(s.resume.Mobs)
+		player.Restore(s.resume.Player)
+		s.resume = nil
+	} else {
+		level := dungeonsCleared + 1
+		stats.Emit(stats.Event{Kind: stats.LevelEntered, Level: level})
+		mobs.ResetMobs()
+		mobs.SetRand(rng.Stream("boss-mobs", level))
+		dungeon.SeedLevel(level)
+
+		player.SetPosition(548, 285)
+
+		mobs.SpawnBossAtPosition(rl.NewVector2(548, 200))
+		spawnArenaExtras()
+		saveRun()
+	}
+
+	audio.PlayTrack("boss")
+}
+
+func (s *bossScene) Exit() {
+	player.ClearExternalColliders()
+	mobs.ClearExternalColliders()
+}
+
+func (s *bossScene) HandleInput() {
+	handleWorldInput()
+}
+
+func (s *bossScene) Update(dt float32) {
+	if player.IsPlayerDead() {
+		player.PlayerMoving(dt)
+		if player.HasPlayerDeathAnimationFinished() {
+			scenes.Push(newGameOverScene())
+		}
+		return
+	}
+
+	spawnArenaExtras()
+	updateCombat(preset.BossDamage, dt)
+	dungeon.UpdatePotionPickup(player.PlayerHitBox)
+
+	if !mobs.IsBossAlive() {
+		stats.Emit(stats.Event{Kind: stats.BossKilled})
+		if endless {
+			dungeonsCleared++
+			scenes.Switch(newDungeonScene())
+			return
+		}
+		finishRun()
+		// The run is over, nothing left to continue
+		deleteSave()
+		scenes.Switch(newTownScene(worldSpawnPos))
+		scenes.Push(newWinScene())
+	}
+}
+
+func (s *bossScene) Draw() {
+	beginWorldDraw(dungeonBgColor)
+
+	boss.Draw()
+	mobs.DrawMobs()
+	dungeon.DrawPotion()
+
+	player.DrawPlayerTexture()
+
+	endWorldDraw()
+
+	if mobs.IsBossAlive() {
+		drawBossHealthBar()
+	}
+}
+
+// spawnArenaExtras keeps the arena topped up with minions and potions.
+func spawnArenaExtras() {
+	mobs.SpawnMobs(20, "random", boss.FloorTiles)
+	dungeon.SpawnPotions(5, boss.FloorTiles, boss.BossMap.TileSize)
+}
+
+func drawBossHealthBar() {
+	current, max, ok := mobs.GetBossHealth()
+	if !ok || max <= 0 {
+		return
+	}
+	percent := current / max
+	if percent < 0 {
+		percent = 0
+	}
+	barW := float32(480)
+	barH := float32(20)
+	barX := float32(rl.GetScreenWidth())/2 - barW/2
+	barY := float32(16)
+	bg := rl.NewRectangle(barX, barY, barW, barH)
+	fg := rl.NewRectangle(barX+2, barY+2, (barW-4)*percent, barH-4)
+	rl.DrawRectangleRec(bg, rl.NewColor(0, 0, 0, 200))
+	color := rl.Color{R: 190, G: 75, B: 75, A: 255}
+	if percent <= 0.2 {
+		color = rl.Color{R: 57, G: 108, B: 60, A: 255}
+	} else if percent <= 0.5 {
+		color = rl.Color{R: 231, G: 152, B: 50, A: 255}
+	}
+	rl.DrawRectangleRec(fg, color)
+}
@@ -0,0 +1,142 @@
+package game
+
+import (
+	"fmt"
+	"os"
+
+	"spooknloot/pkg/daily"
+	"spooknloot/pkg/difficulty"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/ui"
+)
+
+// dailyDate is the date of the daily challenge being played, empty for
+// every other run.
+var dailyDate string
+
+// useDaily applies the rules of the challenge on date: the normal preset
+// changed by its modifiers and its mob mix.
+func useDaily(date string) {
+	dailyDate = date
+	mods := daily.Modifiers(date)
+	preset = daily.Apply(difficulty.Get(difficulty.Default), mods)
+	mobs.SetSpawnTypes(daily.MobTypes(mods))
+	applyStrength()
+	player.SetMaxHealth(playerMaxHealth())
+}
+
+// leaveDaily drops the mob mix of a daily challenge; the caller picks the
+// preset that follows.
+func leaveDaily() {
+	dailyDate = ""
+	mobs.SetSpawnTypes(nil)
+}
+
+// startDaily abandons the current run for the challenge on date. Everyone
+// playing it gets the same seed.
+func startDaily(date string) {
+	resetRun()
+	resetRunMode()
+	useDaily(date)
+	useRunSeed(daily.Seed(date))
+	runStarted = true
+	scenes.Reset(newTownScene(worldSpawnPos))
+}
+
+func addDailyScore(score stats.Score) {
+	d, err := stats.LoadDailyScores()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading daily scores:", err)
+		return
+	}
+	if !d.Add(dailyDate, score) {
+		return
+	}
+	if err := stats.SaveDailyScores(d); err != nil {
+		fmt.Fprintln(os.Stderr, "saving daily scores:", err)
+	}
+}
+
+var dailyItems = []string{"daily.start", "daily.back"}
+
+// dailyScene shows today's challenge and its leaderboard, opened from the
+// start menu.
+type dailyScene struct {
+	date     string
+	mods     []daily.Modifier
+	scores   []stats.Score
+	selected int
+}
+
+func newDailyScene() *dailyScene {
+	return &dailyScene{}
+}
+
+func (s *dailyScene) IsOverlay() bool { return true }
+
+func (s *dailyScene) Enter() {
+	s.date = daily.Today()
+	s.mods = daily.Modifiers(s.date)
+	d, err := stats.LoadDailyScores()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading daily scores:", err)
+	}
+	s.scores = d[s.date]
+}
+
+func (s *dailyScene) Exit() {}
+
+func (s *dailyScene) HandleInput() {
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, len(dailyItems))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetGameOverItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	switch dailyItems[s.selected] {
+	case "daily.start":
+		startDaily(s.date)
+	case "daily.back":
+		scenes.Pop()
+	}
+}
+
+func (s *dailyScene) Update(dt float32) {}
+
+func (s *dailyScene) Draw() {
+	lines := []ui.SummaryLine{{Label: i18n.T("daily.modifiers")}}
+	for _, m := range s.mods {
+		lines = append(lines, ui.SummaryLine{
+			Label:  i18n.Or("modifier."+m.ID+".name", m.Name),
+			Value:  i18n.Or("modifier."+m.ID+".description", m.Description),
+			Indent: true,
+		})
+	}
+
+	lines = append(lines, ui.SummaryLine{Label: i18n.T("daily.best")})
+	if len(s.scores) == 0 {
+		lines = append(lines, ui.SummaryLine{Label: i18n.T("daily.noScores"), Indent: true})
+	}
+	for i, sc := range s.scores {
+		lines = append(lines, ui.SummaryLine{
+			Label:  fmt.Sprintf("%d. %s", i+1, i18n.T("highscores.depthIn", depthName(sc.Depth), formatDuration(sc.Time))),
+			Value:  i18n.N("highscores.killCount", sc.Kills),
+			Indent: true,
+		})
+	}
+
+	ui.DrawSummaryScreen(i18n.T("daily.title", s.date), lines, translate(dailyItems), s.selected)
+}
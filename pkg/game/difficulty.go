@@ -0,0 +1,77 @@
+package game
+
+import (
+	"spooknloot/pkg/difficulty"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/settings"
+	"spooknloot/pkg/ui"
+)
+
+// difficultyScene picks the preset of a new run, opened by "New Game". The
+// last choice is remembered in the settings.
+type difficultyScene struct {
+	selected int
+}
+
+func newDifficultyScene() *difficultyScene {
+	return &difficultyScene{}
+}
+
+func (s *difficultyScene) IsOverlay() bool { return true }
+
+func (s *difficultyScene) Enter() {
+	for i, p := range difficulty.All() {
+		if p.ID == preset.ID {
+			s.selected = i
+		}
+	}
+}
+
+func (s *difficultyScene) Exit() {}
+
+func (s *difficultyScene) HandleInput() {
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+
+	presets := difficulty.All()
+	s.selected = navigateMenu(s.selected, len(presets))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetPauseItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	id := presets[s.selected].ID
+	changeSettings(func(s *settings.Settings) {
+		s.Difficulty = id
+	})
+	deleteSave()
+	useDifficulty(id)
+	runStarted = true
+	// Close this and the start menu below it
+	scenes.Pop()
+	scenes.Pop()
+}
+
+func (s *difficultyScene) Update(dt float32) {}
+
+func (s *difficultyScene) Draw() {
+	presets := difficulty.All()
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = difficultyName(p)
+	}
+	p := presets[s.selected]
+	ui.DrawPauseMenu(i18n.T("difficulty.title"), names, s.selected, i18n.Or("difficulty."+p.ID+".description", p.Description))
+}
+
+// difficultyName is the display name of a preset.
+func difficultyName(p difficulty.Preset) string {
+	return i18n.Or("difficulty."+p.ID+".name", p.Name)
+}
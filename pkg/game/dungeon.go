@@ -0,0 +1,159 @@
+package game
+
+import (
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/save"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/world"
+)
+
+// dungeonScene is a single generated dungeon level. Clearing it switches to
+// the next level, or to the boss arena after the last one (and every few
+// levels in endless mode).
+type dungeonScene struct {
+	exitCooldown    float32
+	exitSoundPlayed bool
+	mobsClearTime   float32
+
+	resume *save.Run
+}
+
+func newDungeonScene() *dungeonScene {
+	return &dungeonScene{}
+}
+
+func (s *dungeonScene) Enter() {
+	applyStrength()
+	if s.resume != nil && s.resume.Dungeon != nil {
+		dungeon.Restore(*s.resume.Dungeon)
+		player.SetExternalColliders(dungeon.GetColliders())
+		mobs.SetExternalColliders(dungeon.GetColliders())
+		mobs.Restore(s.resume.Mobs)
+		player.Restore(s.resume.Player)
+		s.exitCooldown = s.resume.ExitCooldown
+		s.exitSoundPlayed = s.resume.ExitSoundPlayed
+		s.mobsClearTime = s.resume.MobsClearTime
+		s.resume = nil
+		audio.PlayTrack("dungeon")
+		return
+	}
+
+	level := dungeonsCleared + 1
+	stats.Emit(stats.Event{Kind: stats.LevelEntered, Level: level})
+	dungeon.Generate(level)
+	player.SetExternalColliders(dungeon.GetColliders())
+	mobs.SetExternalColliders(dungeon.GetColliders())
+	spawn := dungeon.GetSpawnPosition()
+	player.SetPosition(spawn.X, spawn.Y)
+	s.exitCooldown = exitCooldownDefault
+
+	r := rng.Stream("spawn-count", level)
+	baseMin, baseMax := preset.SpawnMin+ngPlusSpawns*ngPlus, preset.SpawnMax+ngPlusSpawns*ngPlus
+	maxCount := 2*baseMax + endlessDepth(level)
+	if dungeonSpawnCount == 0 {
+		dungeonSpawnCount = baseMin + r.Intn(baseMax-baseMin+1)
+	} else {
+		inc := 2 + r.Intn(4)
+		dungeonSpawnCount += inc
+		if dungeonSpawnCount > maxCount {
+			dungeonSpawnCount = maxCount
+		}
+	}
+
+	// The guards of special rooms and the key carriers are part of the
+	// level's mobs, not extra
+	mobs.ResetMobs()
+	mobs.SetRand(rng.Stream("mobs", level))
+	holders := dungeon.SpawnKeyHolders()
+	guards := dungeon.GuardPositions()
+	positions := dungeon.GetRandomFloorPositions(max(dungeonSpawnCount-len(guards)-holders, 0))
+	mobs.SpawnMobsAtPositions(positions, "random")
+	mobs.SpawnMobsAtPositions(guards, "random")
+
+	s.exitSoundPlayed = false
+	s.mobsClearTime = 0
+
+	audio.PlayTrack("dungeon")
+	saveRun()
+}
+
+func (s *dungeonScene) Exit() {
+	player.ClearExternalColliders()
+	mobs.ClearExternalColliders()
+	mobs.ResetMobs()
+}
+
+func (s *dungeonScene) HandleInput() {
+	handleWorldInput()
+}
+
+func (s *dungeonScene) Update(dt float32) {
+	if player.IsPlayerDead() {
+		player.PlayerMoving(dt)
+		if player.HasPlayerDeathAnimationFinished() {
+			scenes.Push(newGameOverScene())
+		}
+		return
+	}
+
+	updateCombat(preset.MobDamage, dt)
+	dungeon.UpdatePotionPickup(player.PlayerHitBox)
+	dungeon.UpdateTraps(player.PlayerHitBox, dt)
+	roomsChanged := dungeon.UpdateRooms(player.PlayerHitBox)
+	locksChanged := dungeon.UpdateLocks(player.PlayerHitBox)
+	if roomsChanged || locksChanged {
+		player.SetExternalColliders(dungeon.GetColliders())
+		mobs.SetExternalColliders(dungeon.GetColliders())
+	}
+
+	if s.exitCooldown > 0 {
+		s.exitCooldown -= dt
+	}
+
+	if mobs.IsMobAlive() {
+		dungeon.HideExit()
+		s.exitSoundPlayed = false
+		s.mobsClearTime = 0
+	} else {
+		if s.mobsClearTime < exitRevealDelay {
+			s.mobsClearTime += dt
+		}
+		if s.mobsClearTime >= exitRevealDelay {
+			dungeon.ShowExit()
+			if !s.exitSoundPlayed {
+				world.PlayDoorOpenSound()
+				s.exitSoundPlayed = true
+			}
+		}
+	}
+
+	if s.exitCooldown <= 0 && !mobs.IsMobAlive() && dungeon.IsPlayerAtExit(player.PlayerHitBox) {
+		dungeonsCleared++
+		if isBossLevel(dungeonsCleared + 1) {
+			scenes.Switch(newBossScene())
+		} else {
+			scenes.Switch(newDungeonScene())
+		}
+	}
+}
+
+func (s *dungeonScene) Draw() {
+	beginWorldDraw(dungeonBgColor)
+
+	dungeon.Draw()
+	mobs.DrawMobs()
+	dungeon.DrawKeyHolders()
+	player.DrawPlayerTexture()
+	dungeon.DrawTrapEffects()
+
+	var keys []string
+	if n := dungeon.HeldKeys(); n > 0 {
+		keys = append(keys, i18n.N("hud.keys", n))
+	}
+	endWorldDraw(keys...)
+}
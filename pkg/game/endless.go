@@ -0,0 +1,191 @@
+package game
+
+import (
+	"fmt"
+
+	"spooknloot/pkg/difficulty"
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/ui"
+)
+
+const (
+	// Every New Game+ cycle makes mobs and the boss this much stronger and
+	// adds this many mobs to every level.
+	ngPlusGrowth float32 = 0.25
+	ngPlusSpawns         = 2
+
+	// Every endless level past the boss arena makes mobs this much stronger
+	// and allows one more mob per level.
+	endlessGrowth float32 = 0.05
+	// In endless mode the boss comes back every endlessBossEvery levels.
+	endlessBossEvery = 10
+)
+
+// reward is a bonus picked before a New Game+ cycle. Rewards stack and are
+// kept for every following cycle.
+type reward struct {
+	ID string
+	// Bonus is added per pick, e.g. 0.25 for +25% per pick.
+	Bonus float32
+}
+
+var rewardList = []reward{
+	// more max health
+	{ID: "vitality", Bonus: 0.25},
+	// more damage dealt
+	{ID: "might", Bonus: 0.2},
+	// less damage taken
+	{ID: "armor", Bonus: 0.2},
+}
+
+var (
+	// endless keeps the run going past the boss arena.
+	endless bool
+	// ngPlus counts the New Game+ cycles of the current run.
+	ngPlus int
+	// rewards counts the picks of each reward ID.
+	rewards map[string]int
+)
+
+// rewardFactor is 1 plus the bonus of every pick of the reward id.
+func rewardFactor(id string) float32 {
+	for _, r := range rewardList {
+		if r.ID == id {
+			return 1 + r.Bonus*float32(rewards[id])
+		}
+	}
+	return 1
+}
+
+// isBossLevel reports whether level is played in the boss arena.
+func isBossLevel(level int) bool {
+	if level == bossLevel {
+		return true
+	}
+	return endless && level > bossLevel && (level-bossLevel)%endlessBossEvery == 0
+}
+
+// endlessDepth is how many levels past the first boss arena level is.
+func endlessDepth(level int) int {
+	if level <= bossLevel {
+		return 0
+	}
+	return level - bossLevel
+}
+
+// strength scales mob and boss health and damage for the current level.
+func strength() float32 {
+	return 1 + ngPlusGrowth*float32(ngPlus) + endlessGrowth*float32(endlessDepth(dungeonsCleared+1))
+}
+
+// applyStrength hands the scaled difficulty of the current level to the
+// mobs and dungeon packages. Call whenever a level is entered.
+func applyStrength() {
+	f := strength()
+	mobs.SetStrength(preset.MobHealth*f, preset.BossHealth*f, preset.BossArmor)
+	// Trap damage is tuned for the default preset
+	traps := f / rewardFactor("armor")
+	if normal := difficulty.Get(difficulty.Default).MobDamage; normal > 0 {
+		traps *= preset.MobDamage / normal
+	}
+	dungeon.SetTrapStrength(traps)
+}
+
+// playerMaxHealth is the health the player starts a run or cycle with.
+func playerMaxHealth() float32 {
+	return preset.PlayerHealth * rewardFactor("vitality")
+}
+
+// resetRunMode leaves endless mode, New Game+ and the daily challenge for a
+// fresh run.
+func resetRunMode() {
+	endless = false
+	ngPlus = 0
+	rewards = nil
+	if dailyDate != "" {
+		leaveDaily()
+		preset = difficulty.Get(startDifficulty())
+	}
+	applyStrength()
+	player.SetMaxHealth(playerMaxHealth())
+}
+
+// startEndless continues a won run with the levels past the boss arena.
+func startEndless() {
+	endless = true
+	// The arena counts as cleared
+	dungeonsCleared = bossLevel
+	runStarted = true
+	scenes.Reset(newTownScene(worldSpawnPos))
+	scenes.Switch(newDungeonScene())
+}
+
+// startNewGamePlus starts the 20 levels over, harder, with pick added to
+// the rewards kept so far.
+func startNewGamePlus(pick string) {
+	cycle := ngPlus + 1
+	kept := map[string]int{pick: 1}
+	for id, n := range rewards {
+		kept[id] += n
+	}
+
+	resetRun()
+	ngPlus = cycle
+	rewards = kept
+	applyStrength()
+	player.SetMaxHealth(playerMaxHealth())
+
+	runStarted = true
+	scenes.Reset(newTownScene(worldSpawnPos))
+}
+
+// rewardScene picks the reward for the next New Game+ cycle, opened from
+// the win screen.
+type rewardScene struct {
+	selected int
+}
+
+func newRewardScene() *rewardScene {
+	return &rewardScene{}
+}
+
+func (s *rewardScene) IsOverlay() bool { return true }
+
+func (s *rewardScene) Enter() {}
+
+func (s *rewardScene) Exit() {}
+
+func (s *rewardScene) HandleInput() {
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, len(rewardList))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetPauseItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if choose {
+		startNewGamePlus(rewardList[s.selected].ID)
+	}
+}
+
+func (s *rewardScene) Update(dt float32) {}
+
+func (s *rewardScene) Draw() {
+	names := make([]string, len(rewardList))
+	for i, r := range rewardList {
+		names[i] = i18n.T("reward." + r.ID + ".name")
+		if n := rewards[r.ID]; n > 0 {
+			names[i] += fmt.Sprintf(" (%d)", n)
+		}
+	}
+	hint := i18n.T("reward."+rewardList[s.selected].ID+".description", int(rewardList[s.selected].Bonus*100+0.5))
+	ui.DrawPauseMenu(i18n.T("reward.title"), names, s.selected, hint)
+}
@@ -0,0 +1,438 @@
+package game
+
+import (
+	"fmt"
+	"os"
+
+	"spooknloot/pkg/achievements"
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/boss"
+	"spooknloot/pkg/daily"
+	"spooknloot/pkg/debug"
+	"spooknloot/pkg/difficulty"
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/scene"
+	"spooknloot/pkg/settings"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/ui"
+	"spooknloot/pkg/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// TickRate is the fixed number of simulation updates per second.
+	TickRate = 60
+	// TickDelta is the duration of one simulation update in seconds.
+	TickDelta float32 = 1.0 / TickRate
+
+	exitCooldownDefault float32 = 20.0 / 60
+	exitRevealDelay     float32 = 0.1
+	dungeonLevels               = 20
+)
+
+var (
+	worldBgColor   = rl.NewColor(143, 77, 87, 1)
+	dungeonBgColor = rl.NewColor(41, 29, 43, 1)
+
+	printDebug  bool
+	musicPaused bool
+
+	scenes scene.Manager
+
+	dungeonsCleared   int
+	savedWorldPos     rl.Vector2
+	dungeonSpawnCount int
+
+	worldSpawnPos = rl.NewVector2(495, 344)
+
+	quitRequested bool
+
+	// preset is the difficulty of the current run.
+	preset difficulty.Preset
+
+	// fixedSeed is the seed requested on the command line; 0 picks a fresh
+	// seed for every run.
+	fixedSeed int64
+)
+
+// SetSeed makes every run use seed, so the same dungeon layouts, mobs,
+// potions and torches come up again. 0 restores random runs. Call before Init.
+func SetSeed(seed int64) {
+	fixedSeed = seed
+}
+
+// SetBindings makes the run use these action bindings instead of the
+// player's, e.g. the ones a replay was recorded with. Call before Init.
+func SetBindings(saved map[string][]string) {
+	input.UseFixed(saved)
+}
+
+// Seed is the seed of the current run.
+func Seed() int64 {
+	return rng.Seed()
+}
+
+func startRunSeed() {
+	seed := fixedSeed
+	if seed == 0 {
+		seed = rng.NewSeed()
+	}
+	useRunSeed(seed)
+}
+
+func useRunSeed(seed int64) {
+	rng.SetSeed(seed)
+	ui.SetRunSeed(seed)
+}
+
+// Init loads every subsystem and opens the start menu on top of the town.
+// The window and audio device must already be initialised.
+func Init() {
+	if err := i18n.Load("assets/lang"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading translations:", err)
+	}
+	applyLanguage()
+	applyVolumes()
+	audio.Init()
+
+	world.InitWorld()
+	world.InitDoors()
+	world.InitLamps()
+	world.InitPumpkinLamps()
+
+	world.LoadMap("pkg/world/map.json")
+
+	player.InitPlayer()
+	mobs.InitMobs()
+
+	dungeon.Init()
+	if err := dungeon.LoadRoomTypes("pkg/dungeon/rooms.json"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading room types:", err)
+	}
+	if err := dungeon.LoadPrefabs("pkg/dungeon/prefabs"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading room prefabs:", err)
+	}
+	boss.Init()
+	boss.LoadMap("pkg/boss/map.json")
+
+	if !savingEnabled {
+		achievements.Use(achievements.MemoryBackend{})
+	}
+	if err := achievements.Init("pkg/achievements/achievements.json"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading achievements:", err)
+	}
+
+	if err := difficulty.Load("pkg/difficulty/difficulty.json"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading difficulty presets:", err)
+	}
+	useDifficulty(startDifficulty())
+	if err := daily.Load("pkg/daily/modifiers.json"); err != nil {
+		fmt.Fprintln(os.Stderr, "loading daily modifiers:", err)
+	}
+
+	input.Load()
+	printDebug = settings.Current.Debug
+	musicPaused = settings.Current.MusicMuted
+	if musicPaused {
+		audio.Pause()
+	}
+
+	startRunSeed()
+	ui.InitMenu("assets/ui/map.png")
+
+	scenes.Push(newTownScene(worldSpawnPos))
+	scenes.Push(newStartMenuScene())
+}
+
+// HandleInput processes the global hotkeys and forwards the rest to the
+// active scene.
+func HandleInput() {
+	input.BeginFrame()
+	if capturingInput() {
+		scenes.HandleInput()
+		return
+	}
+
+	if input.Pressed(input.ToggleFullscreen) {
+		toggleFullscreen()
+	}
+
+	if input.Pressed(input.ToggleMusic) {
+		musicPaused = !musicPaused
+		if musicPaused {
+			audio.Pause()
+		} else {
+			audio.Resume()
+		}
+		changeSettings(func(s *settings.Settings) { s.MusicMuted = musicPaused })
+	}
+
+	if input.Pressed(input.ToggleDebug) {
+		printDebug = !printDebug
+	}
+
+	scenes.HandleInput()
+}
+
+func toggleFullscreen() {
+	backend.Current.ToggleBorderlessWindowed()
+	fullscreen := !settings.Current.Fullscreen
+	changeSettings(func(s *settings.Settings) { s.Fullscreen = fullscreen })
+}
+
+// changeSettings applies an in-game preference change and remembers it
+// for the next start.
+func changeSettings(fn func(s *settings.Settings)) {
+	if err := settings.Change(fn); err != nil {
+		fmt.Fprintln(os.Stderr, "saving settings:", err)
+	}
+}
+
+// applyVolumes feeds the volume settings into the audio buses.
+func applyVolumes() {
+	audio.SetBusVolume(audio.Master, settings.Current.MasterVolume)
+	audio.SetBusVolume(audio.Music, settings.Current.MusicVolume)
+	audio.SetBusVolume(audio.SFX, settings.Current.SoundVolume)
+}
+
+// useDifficulty makes the current run use the preset id, see
+// difficulty.Get for unknown IDs. The player starts at full health.
+func useDifficulty(id string) {
+	leaveDaily()
+	preset = difficulty.Get(id)
+	applyStrength()
+	player.SetMaxHealth(playerMaxHealth())
+}
+
+// startDifficulty is the preset a new run starts on. Replays and the
+// simulation use the default preset so they don't depend on the player's
+// last choice.
+func startDifficulty() string {
+	if savingEnabled {
+		return settings.Current.Difficulty
+	}
+	return difficulty.Default
+}
+
+// applyLanguage switches the text catalog to the language setting, or to
+// the system language if there is none.
+func applyLanguage() {
+	code := settings.Current.Language
+	if code == "" {
+		code = i18n.SystemLanguage()
+	}
+	i18n.SetLanguage(code)
+}
+
+// Update advances the game by one fixed tick of dt seconds.
+func Update(dt float32) {
+	audio.Update(dt)
+	updateToasts(dt)
+	scenes.Update(dt)
+}
+
+// Draw renders the current scenes. alpha is the fraction of a tick that has
+// elapsed since the last Update and is used to interpolate moving sprites.
+func Draw(alpha float32) {
+	if o, ok := scenes.Current().(scene.Overlay); ok && o.IsOverlay() {
+		// Nothing moves underneath a menu
+		alpha = 1
+	}
+	player.SetInterpolation(alpha)
+	mobs.SetInterpolation(alpha)
+
+	scenes.Draw()
+	drawToast()
+
+	if printDebug {
+		debug.DrawDebug(debug.DebugText())
+	}
+}
+
+// QuitRequested reports whether the player chose Quit in a menu. The main
+// loop should stop and call Unload.
+func QuitRequested() bool {
+	return quitRequested
+}
+
+func quit() {
+	quitRequested = true
+}
+
+func Unload() {
+	saveRun()
+	for scenes.Len() > 0 {
+		scenes.Pop()
+	}
+	audio.Unload()
+	achievements.Flush()
+	player.UnloadPlayerTexture()
+	world.UnloadWorldTexture()
+	world.UnloadDoorsTextures()
+	world.UnloadPumpkinLamps()
+	mobs.UnloadMobsTexture()
+	dungeon.Unload()
+	ui.UnloadMenu()
+}
+
+// CurrentScene names the active scene: "town", "dungeon", "boss", "menu",
+// "pause", "settings", "highscores", "gameover", "win", "bindings", "difficulty", "reward" or "daily". Used by tools that drive the game without a window.
+func CurrentScene() string {
+	switch scenes.Current().(type) {
+	case *townScene:
+		return "town"
+	case *dungeonScene:
+		return "dungeon"
+	case *bossScene:
+		return "boss"
+	case *menuScene:
+		return "menu"
+	case *pauseScene:
+		return "pause"
+	case *optionsScene:
+		return "settings"
+	case *highScoresScene:
+		return "highscores"
+	case *gameOverScene:
+		return "gameover"
+	case *winScene:
+		return "win"
+	case *bindingsScene:
+		return "bindings"
+	case *difficultyScene:
+		return "difficulty"
+	case *rewardScene:
+		return "reward"
+	case *dailyScene:
+		return "daily"
+	}
+	return ""
+}
+
+// DungeonsCleared is how many dungeon levels the current run has finished.
+func DungeonsCleared() int {
+	return dungeonsCleared
+}
+
+// resetRun forgets all progress of the current run after the player died.
+func resetRun() {
+	dungeonsCleared = 0
+	dungeonSpawnCount = 0
+	deleteSave()
+	startRunSeed()
+	stats.Reset()
+
+	mobs.ResetMobs()
+	player.ResetPlayer()
+}
+
+// restartRun abandons the current run and starts a new one in town.
+func restartRun() {
+	resetRun()
+	resetRunMode()
+	runStarted = true
+	scenes.Reset(newTownScene(worldSpawnPos))
+}
+
+// retryRun starts the current run over with the same seed, straight in the
+// level it started at: the first one, or the first past the boss arena in
+// endless mode. Endless mode and New Game+ stay as they were.
+func retryRun() {
+	seed := rng.Seed()
+	resetRun()
+	useRunSeed(seed)
+	if endless {
+		// Like startEndless, the arena counts as cleared
+		dungeonsCleared = bossLevel
+	}
+	runStarted = true
+	scenes.Reset(newTownScene(worldSpawnPos))
+	scenes.Switch(newDungeonScene())
+}
+
+func playerCenter() rl.Vector2 {
+	return rl.NewVector2(player.PlayerHitBox.X+(player.PlayerHitBox.Width/2), player.PlayerHitBox.Y+(player.PlayerHitBox.Height/2))
+}
+
+// updateCombat moves the player and mobs and resolves attacks in both
+// directions. mobDamage is what a single mob hit costs the player before
+// level scaling and rewards.
+func updateCombat(mobDamage float32, dt float32) {
+	stats.Advance(dt)
+	player.PlayerMoving(dt)
+
+	mobDamage *= strength() / rewardFactor("armor")
+	might := rewardFactor("might") * player.BuffFactor("damage")
+
+	playerPos := playerCenter()
+	mobs.MobMoving(playerPos, func() {
+		player.SetPlayerDamageState()
+		player.TakeDamage(mobDamage)
+	}, dt)
+
+	if mobs.IsMobAlive() {
+		closestMobIndex := mobs.GetClosestMobIndex(playerPos)
+		if closestMobIndex != -1 {
+			mobCenter := mobs.GetMobHitboxCenterByIndex(closestMobIndex)
+			player.TryAttack(mobCenter, func(damage float32) {
+				mobs.DamageMob(closestMobIndex, damage*might)
+			})
+		}
+	}
+}
+
+// handleWorldInput is shared by all scenes the player walks around in.
+func handleWorldInput() {
+	// The boss shortcut is a debug cheat; it would skew scores and achievements
+	// and is never allowed in a daily challenge
+	if printDebug && dailyDate == "" && input.Pressed(input.BossRoom) {
+		if _, ok := scenes.Current().(*bossScene); !ok {
+			scenes.Switch(newBossScene())
+			return
+		}
+	}
+
+	if input.Pressed(input.Pause) {
+		scenes.Push(newPauseScene())
+		return
+	}
+
+	player.PlayerInput()
+}
+
+// beginWorldDraw clears the screen and starts the camera pass for a world scene.
+func beginWorldDraw(bg rl.Color) {
+	rl.ClearBackground(bg)
+	rl.BeginMode2D(player.Cam)
+}
+
+// endWorldDraw ends the world camera and draws the HUD on top; lines are
+// listed after the buffs.
+func endWorldDraw(lines ...string) {
+	if printDebug {
+		debug.DrawPlayerOutlines()
+	}
+	rl.EndMode2D()
+
+	player.DrawHealthBar()
+	drawBuffs(lines)
+}
+
+// drawBuffs lists the player's shrine buffs below the health bar, followed
+// by the extra lines.
+func drawBuffs(extra []string) {
+	var lines []string
+	for _, b := range player.Buffs() {
+		lines = append(lines, i18n.Or("buff."+b.Kind, b.Kind+" %ds", int(b.Time+0.5)))
+	}
+	lines = append(lines, extra...)
+	bar := player.HealthBarRect()
+	ui.DrawBuffs(lines, bar.X, bar.Y+bar.Height+6)
+}
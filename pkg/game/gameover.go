@@ -0,0 +1,113 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/ui"
+)
+
+var gameOverItems = []string{"gameover.retry", "gameover.returnToTown"}
+
+// gameOverScene is shown over the scene the player died in once the death
+// animation has finished. It sums up the run and lets the player start over.
+type gameOverScene struct {
+	selected int
+}
+
+func newGameOverScene() *gameOverScene {
+	return &gameOverScene{}
+}
+
+func (s *gameOverScene) IsOverlay() bool { return true }
+
+func (s *gameOverScene) Enter() {
+	stats.Emit(stats.Event{Kind: stats.PlayerDied})
+	finishRun()
+	// A dead run can't be continued, even if the game is closed here
+	deleteSave()
+}
+
+func (s *gameOverScene) Exit() {}
+
+func (s *gameOverScene) HandleInput() {
+	s.selected = navigateMenu(s.selected, len(gameOverItems))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetGameOverItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	switch gameOverItems[s.selected] {
+	case "gameover.retry":
+		retryRun()
+	case "gameover.returnToTown":
+		restartRun()
+	}
+}
+
+func (s *gameOverScene) Update(dt float32) {}
+
+func (s *gameOverScene) Draw() {
+	run := stats.Current()
+	lines := []ui.SummaryLine{
+		{Label: i18n.T("gameover.difficulty"), Value: difficultyName(preset)},
+	}
+	if dailyDate != "" {
+		lines[0] = ui.SummaryLine{Label: i18n.T("gameover.daily"), Value: dailyDate}
+	}
+	if ngPlus > 0 {
+		lines = append(lines, ui.SummaryLine{Label: i18n.T("gameover.ngPlus"), Value: fmt.Sprint(ngPlus)})
+	}
+	if endless {
+		lines = append(lines, ui.SummaryLine{Label: i18n.T("gameover.mode"), Value: i18n.T("gameover.endless")})
+	}
+	lines = append(lines, []ui.SummaryLine{
+		{Label: i18n.T("gameover.depth"), Value: depthName(run.Depth)},
+		{Label: i18n.T("gameover.time"), Value: formatDuration(run.Time)},
+		{Label: i18n.T("gameover.damageDealt"), Value: fmt.Sprintf("%.0f", run.DamageDealt)},
+		{Label: i18n.T("gameover.damageTaken"), Value: fmt.Sprintf("%.0f", run.DamageTaken)},
+		{Label: i18n.T("gameover.potions"), Value: fmt.Sprint(run.Potions)},
+		{Label: i18n.T("gameover.kills"), Value: fmt.Sprint(run.TotalKills())},
+	}...)
+
+	types := make([]string, 0, len(run.Kills))
+	for t := range run.Kills {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		lines = append(lines, ui.SummaryLine{Label: mobName(t), Value: fmt.Sprint(run.Kills[t]), Indent: true})
+	}
+
+	ui.DrawGameOver(lines, translate(gameOverItems), s.selected)
+}
+
+// formatDuration formats seconds as m:ss.
+func formatDuration(seconds float32) string {
+	total := int(seconds)
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// depthName describes how deep a run got, see stats.LevelEntered.
+func depthName(depth int) string {
+	switch {
+	case depth <= 0:
+		return i18n.T("depth.town")
+	case depth == bossLevel:
+		return i18n.T("depth.boss")
+	}
+	return i18n.T("depth.level", depth)
+}
+
+// mobName is the display name of a mob type, see mobs.SpawnMobs. Types
+// without a translation show up as they are.
+func mobName(mobType string) string {
+	return i18n.Or("mob."+mobType, mobType)
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/ui"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// menuScene is the start screen shown over the town. It offers to continue
+// a saved run.
+type menuScene struct {
+	items    []string
+	selected int
+}
+
+func newStartMenuScene() *menuScene {
+	return &menuScene{}
+}
+
+func (s *menuScene) IsOverlay() bool { return true }
+
+func (s *menuScene) Enter() {
+	s.items = s.menuItems()
+	s.selected = 0
+}
+
+func (s *menuScene) Exit() {}
+
+func (s *menuScene) menuItems() []string {
+	var items []string
+	if canContinue() {
+		items = append(items, "menu.continue")
+	}
+	return append(items, "menu.newGame", "menu.daily", "menu.highScores", "menu.settings", "menu.quit")
+}
+
+func (s *menuScene) HandleInput() {
+	if input.Pressed(input.Pause) {
+		// The start screen can only be skipped this way if there is no
+		// saved run the player might have wanted instead
+		if !canContinue() {
+			runStarted = true
+			scenes.Pop()
+		}
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, len(s.items))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetMenuItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	switch s.items[s.selected] {
+	case "menu.highScores":
+		scenes.Push(newHighScoresScene())
+		return
+	case "menu.settings":
+		scenes.Push(newOptionsScene())
+		return
+	case "menu.quit":
+		quit()
+		return
+	case "menu.newGame":
+		scenes.Push(newDifficultyScene())
+		return
+	case "menu.daily":
+		scenes.Push(newDailyScene())
+		return
+	case "menu.continue":
+		if continueRun() {
+			return
+		}
+	}
+	runStarted = true
+	scenes.Pop()
+}
+
+func (s *menuScene) Update(dt float32) {}
+
+func (s *menuScene) Draw() {
+	ui.SetMenuItems(translate(s.items), s.selected)
+	ui.DrawMenuOverlay()
+}
+
+// translate looks up a list of message keys, e.g. menu entries.
+func translate(keys []string) []string {
+	text := make([]string, len(keys))
+	for i, k := range keys {
+		text[i] = i18n.T(k)
+	}
+	return text
+}
+
+// navigateMenu moves a selection up or down a list of n entries. The arrow
+// keys always work so a bad binding can't lock the player out of menus.
+func navigateMenu(selected, n int) int {
+	if n == 0 {
+		return 0
+	}
+	if input.Pressed(input.MoveUp) || backend.Current.IsKeyPressed(rl.KeyUp) {
+		selected = (selected + n - 1) % n
+	}
+	if input.Pressed(input.MoveDown) || backend.Current.IsKeyPressed(rl.KeyDown) {
+		selected = (selected + 1) % n
+	}
+	return selected
+}
+
+// navigateValue reports whether the value of a menu entry should go left
+// (-1) or right (+1) this frame, with the same arrow key fallback.
+func navigateValue() int {
+	d := 0
+	if input.Pressed(input.MoveLeft) || backend.Current.IsKeyPressed(rl.KeyLeft) {
+		d--
+	}
+	if input.Pressed(input.MoveRight) || backend.Current.IsKeyPressed(rl.KeyRight) {
+		d++
+	}
+	return d
+}
+
+// clickedRect returns the index of the rectangle the left mouse button was
+// pressed in this frame, or -1.
+func clickedRect(rects []rl.Rectangle) int {
+	if !backend.Current.IsMouseButtonPressed(rl.MouseLeftButton) {
+		return -1
+	}
+	mp := backend.Current.MousePosition()
+	for i, r := range rects {
+		if r.Width > 0 && mp.X >= r.X && mp.X <= r.X+r.Width && mp.Y >= r.Y && mp.Y <= r.Y+r.Height {
+			return i
+		}
+	}
+	return -1
+}
+
+var winItems = []string{"win.newGamePlus", "win.endless", "win.restart"}
+
+// winScene is shown over the town after the boss has been defeated. The run
+// can go on in New Game+ or endless mode, or start over. A daily challenge
+// ends with the boss.
+type winScene struct {
+	items    []string
+	selected int
+}
+
+func newWinScene() *winScene {
+	return &winScene{}
+}
+
+func (s *winScene) IsOverlay() bool { return true }
+
+func (s *winScene) Enter() {
+	s.items = winItems
+	if dailyDate != "" {
+		s.items = []string{"win.restart"}
+	}
+}
+
+func (s *winScene) Exit() {}
+
+func (s *winScene) HandleInput() {
+	s.selected = navigateMenu(s.selected, len(s.items))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetBossWinItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	switch s.items[s.selected] {
+	case "win.newGamePlus":
+		scenes.Push(newRewardScene())
+	case "win.endless":
+		startEndless()
+	case "win.restart":
+		restartRun()
+	}
+}
+
+func (s *winScene) Update(dt float32) {}
+
+func (s *winScene) Draw() {
+	ui.DrawBossWinOverlay(i18n.T("win.title"), translate(s.items), s.selected)
+}
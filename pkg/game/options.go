@@ -0,0 +1,237 @@
+package game
+
+import (
+	"fmt"
+
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/settings"
+	"spooknloot/pkg/ui"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Rows of the settings screen, top to bottom.
+const (
+	optMaster = iota
+	optMusic
+	optSound
+	optFullscreen
+	optWindowSize
+	optLanguage
+	optBindings
+	optBack
+	optCount
+)
+
+const volumeStep float32 = 0.1
+
+// windowScales multiply the game's 500x300 base resolution.
+var windowScales = []float32{2, 2.5, 3, 3.5, 4}
+
+// optionsScene changes the settings in place. Every change takes effect
+// immediately and is written to the settings file.
+type optionsScene struct {
+	selected int
+	// dragging is the slider row held with the mouse, -1 for none.
+	dragging int
+}
+
+func newOptionsScene() *optionsScene {
+	return &optionsScene{dragging: -1}
+}
+
+func (s *optionsScene) IsOverlay() bool { return true }
+
+func (s *optionsScene) Enter() {}
+
+func (s *optionsScene) Exit() {}
+
+func (s *optionsScene) HandleInput() {
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+
+	if s.dragging >= 0 {
+		if !backend.Current.IsMouseButtonDown(rl.MouseLeftButton) {
+			s.dragging = -1
+		} else {
+			s.dragSlider(s.dragging)
+		}
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, optCount)
+	if d := navigateValue(); d != 0 {
+		s.change(s.selected, d)
+	}
+	if input.Pressed(input.Confirm) {
+		s.activate(s.selected)
+	}
+
+	if i := clickedRect(ui.GetOptionSliderRects()); i >= 0 {
+		s.selected = i
+		s.dragging = i
+		s.dragSlider(i)
+	} else if i := clickedRect(ui.GetOptionRowRects()); i >= 0 {
+		s.selected = i
+		s.activate(i)
+	}
+}
+
+// change steps the value of a row left (-1) or right (+1).
+func (s *optionsScene) change(row, d int) {
+	switch row {
+	case optMaster, optMusic, optSound:
+		setVolume(row, volume(row)+float32(d)*volumeStep)
+	case optFullscreen:
+		toggleFullscreen()
+	case optWindowSize:
+		stepWindowScale(d)
+	case optLanguage:
+		stepLanguage(d)
+	}
+}
+
+func (s *optionsScene) activate(row int) {
+	switch row {
+	case optFullscreen:
+		toggleFullscreen()
+	case optWindowSize:
+		stepWindowScale(1)
+	case optLanguage:
+		stepLanguage(1)
+	case optBindings:
+		scenes.Push(newBindingsScene())
+	case optBack:
+		scenes.Pop()
+	}
+}
+
+func (s *optionsScene) dragSlider(row int) {
+	r := ui.GetOptionSliderRects()[row]
+	if r.Width <= 0 {
+		return
+	}
+	setVolume(row, (backend.Current.MousePosition().X-r.X)/r.Width)
+}
+
+func volume(row int) float32 {
+	switch row {
+	case optMaster:
+		return settings.Current.MasterVolume
+	case optMusic:
+		return settings.Current.MusicVolume
+	}
+	return settings.Current.SoundVolume
+}
+
+// setVolume stores a slider value, rounded to 5% so dragging doesn't
+// rewrite the settings file on every pixel.
+func setVolume(row int, v float32) {
+	v = float32(int(v*20+0.5)) / 20
+	if v < 0 {
+		v = 0
+	}
+	if v > 1 {
+		v = 1
+	}
+	if v == volume(row) {
+		return
+	}
+	changeSettings(func(s *settings.Settings) {
+		switch row {
+		case optMaster:
+			s.MasterVolume = v
+		case optMusic:
+			s.MusicVolume = v
+		case optSound:
+			s.SoundVolume = v
+		}
+	})
+	applyVolumes()
+}
+
+// windowScale is the index of the current window size in windowScales, or
+// -1 if the window has a size of its own.
+func windowScale() int {
+	for i, sc := range windowScales {
+		if int(500*sc) == settings.Current.Width && int(300*sc) == settings.Current.Height {
+			return i
+		}
+	}
+	return -1
+}
+
+func stepWindowScale(d int) {
+	i := windowScale()
+	if i < 0 {
+		// Snap a custom size to the default 3x
+		i = 2
+	} else {
+		i = (i + d + len(windowScales)) % len(windowScales)
+	}
+	w, h := int(500*windowScales[i]), int(300*windowScales[i])
+	changeSettings(func(s *settings.Settings) {
+		s.Width = w
+		s.Height = h
+	})
+	// The borderless window always covers the monitor; the new size is
+	// used once fullscreen is left
+	if !settings.Current.Fullscreen {
+		backend.Current.SetWindowSize(w, h)
+	}
+}
+
+// stepLanguage switches to the previous (-1) or next (+1) language in
+// alphabetical order of their codes.
+func stepLanguage(d int) {
+	langs := i18n.Languages()
+	if len(langs) == 0 {
+		return
+	}
+	i := 0
+	for j, code := range langs {
+		if code == i18n.Language() {
+			i = j
+		}
+	}
+	code := langs[(i+d+len(langs))%len(langs)]
+	changeSettings(func(s *settings.Settings) {
+		s.Language = code
+	})
+	applyLanguage()
+}
+
+func (s *optionsScene) Update(dt float32) {}
+
+func (s *optionsScene) Draw() {
+	rows := make([]ui.OptionRow, optCount)
+	for _, row := range []int{optMaster, optMusic, optSound} {
+		v := volume(row)
+		rows[row] = ui.OptionRow{Value: fmt.Sprintf("%d%%", int(v*100+0.5)), Slider: true, Fraction: v}
+	}
+	rows[optMaster].Label = i18n.T("options.masterVolume")
+	rows[optMusic].Label = i18n.T("options.musicVolume")
+	rows[optSound].Label = i18n.T("options.soundVolume")
+
+	rows[optFullscreen] = ui.OptionRow{Label: i18n.T("options.fullscreen"), Value: i18n.T("options.off")}
+	if settings.Current.Fullscreen {
+		rows[optFullscreen].Value = i18n.T("options.on")
+	}
+
+	size := fmt.Sprintf("%dx%d", settings.Current.Width, settings.Current.Height)
+	if i := windowScale(); i >= 0 {
+		size = fmt.Sprintf("%gx (%s)", windowScales[i], size)
+	}
+	rows[optWindowSize] = ui.OptionRow{Label: i18n.T("options.windowSize"), Value: size}
+	rows[optLanguage] = ui.OptionRow{Label: i18n.T("options.language"), Value: i18n.Name(i18n.Language())}
+	rows[optBindings] = ui.OptionRow{Label: i18n.T("options.bindings"), Value: "..."}
+	rows[optBack] = ui.OptionRow{Label: i18n.T("options.back")}
+
+	hint := i18n.T("options.hint",
+		input.Describe(input.MoveLeft), input.Describe(input.MoveRight), input.Describe(input.Confirm), input.Describe(input.Back))
+	ui.DrawOptionsScreen(rows, s.selected, hint)
+}
@@ -0,0 +1,73 @@
+package game
+
+import (
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/ui"
+)
+
+var pauseItems = []string{"pause.resume", "pause.settings", "pause.restart", "pause.quit"}
+
+// pauseScene is the overlay opened with Pause while playing. The music is
+// paused for as long as it is open.
+type pauseScene struct {
+	selected    int
+	pausedMusic bool
+}
+
+func newPauseScene() *pauseScene {
+	return &pauseScene{}
+}
+
+func (s *pauseScene) IsOverlay() bool { return true }
+
+func (s *pauseScene) Enter() {
+	if !musicPaused {
+		audio.Pause()
+		s.pausedMusic = true
+	}
+}
+
+func (s *pauseScene) Exit() {
+	// The music may have been muted while the menu was open
+	if s.pausedMusic && !musicPaused {
+		audio.Resume()
+	}
+	s.pausedMusic = false
+}
+
+func (s *pauseScene) HandleInput() {
+	if input.Cancelled() {
+		scenes.Pop()
+		return
+	}
+
+	s.selected = navigateMenu(s.selected, len(pauseItems))
+	choose := input.Pressed(input.Confirm)
+	if i := clickedRect(ui.GetPauseItemRects()); i >= 0 {
+		s.selected = i
+		choose = true
+	}
+	if !choose {
+		return
+	}
+
+	switch pauseItems[s.selected] {
+	case "pause.resume":
+		scenes.Pop()
+	case "pause.settings":
+		scenes.Push(newOptionsScene())
+	case "pause.restart":
+		restartRun()
+	case "pause.quit":
+		quit()
+	}
+}
+
+func (s *pauseScene) Update(dt float32) {}
+
+func (s *pauseScene) Draw() {
+	hint := i18n.T("pause.hint", input.Describe(input.Confirm), input.Describe(input.Back))
+	ui.DrawPauseMenu(i18n.T("pause.title"), translate(pauseItems), s.selected, hint)
+}
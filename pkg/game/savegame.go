@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+	"os"
+
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/save"
+	"spooknloot/pkg/scene"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/ui"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+var (
+	savingEnabled = true
+
+	// runStarted is false while the start menu has not been left yet, so
+	// quitting from it does not overwrite the previous save.
+	runStarted bool
+)
+
+// SetSaving turns the save file off, e.g. for replays and the headless
+// simulation which must not depend on or touch the player's save.
+func SetSaving(enabled bool) {
+	savingEnabled = enabled
+}
+
+// canContinue reports whether the start menu should offer "Continue".
+func canContinue() bool {
+	return savingEnabled && save.Exists()
+}
+
+// worldScene is the scene the player walks around in, below any menus.
+func worldScene() scene.Scene {
+	for i := scenes.Len() - 1; i >= 0; i-- {
+		switch s := scenes.At(i).(type) {
+		case *townScene, *dungeonScene, *bossScene:
+			return s
+		}
+	}
+	return nil
+}
+
+// saveRun writes the current run to the save file.
+func saveRun() {
+	if !savingEnabled || !runStarted || player.IsPlayerDead() {
+		return
+	}
+
+	r := &save.Run{
+		Seed:              rng.Seed(),
+		Difficulty:        preset.ID,
+		Endless:           endless,
+		NewGamePlus:       ngPlus,
+		Rewards:           rewards,
+		Daily:             dailyDate,
+		DungeonsCleared:   dungeonsCleared,
+		DungeonSpawnCount: dungeonSpawnCount,
+		WorldX:            savedWorldPos.X,
+		WorldY:            savedWorldPos.Y,
+		Player:            player.Snapshot(),
+		Mobs:              mobs.Snapshot(),
+		Stats:             stats.Current(),
+	}
+	switch s := worldScene().(type) {
+	case *townScene:
+		r.Scene = "town"
+	case *dungeonScene:
+		r.Scene = "dungeon"
+		r.ExitCooldown = s.exitCooldown
+		r.ExitSoundPlayed = s.exitSoundPlayed
+		r.MobsClearTime = s.mobsClearTime
+		d := dungeon.Snapshot()
+		r.Dungeon = &d
+	case *bossScene:
+		r.Scene = "boss"
+		d := dungeon.Snapshot()
+		r.Dungeon = &d
+	default:
+		return
+	}
+
+	if err := save.Write(r); err != nil {
+		fmt.Fprintln(os.Stderr, "saving run:", err)
+	}
+}
+
+// continueRun replaces the current world scene with the saved run. It
+// returns false if there is no usable save.
+func continueRun() bool {
+	if !savingEnabled {
+		return false
+	}
+	r, err := save.Read()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading run:", err)
+		return false
+	}
+
+	rng.SetSeed(r.Seed)
+	ui.SetRunSeed(r.Seed)
+	dungeonsCleared = r.DungeonsCleared
+	endless = r.Endless
+	ngPlus = r.NewGamePlus
+	rewards = r.Rewards
+	useDifficulty(r.Difficulty)
+	if r.Daily != "" {
+		useDaily(r.Daily)
+	}
+	dungeonSpawnCount = r.DungeonSpawnCount
+	savedWorldPos = rl.NewVector2(r.WorldX, r.WorldY)
+	stats.Restore(r.Stats)
+
+	var next scene.Scene
+	switch r.Scene {
+	case "dungeon":
+		next = &dungeonScene{resume: r}
+	case "boss":
+		next = &bossScene{resume: r}
+	default:
+		next = &townScene{spawn: worldSpawnPos, resume: r}
+	}
+	scenes.Reset(next)
+	runStarted = true
+	return true
+}
+
+func deleteSave() {
+	if !savingEnabled {
+		return
+	}
+	if err := save.Delete(); err != nil {
+		fmt.Fprintln(os.Stderr, "deleting save:", err)
+	}
+}
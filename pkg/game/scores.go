@@ -0,0 +1,106 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"spooknloot/pkg/achievements"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/ui"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// finishRun is called once a run is won or lost. It enters the run into
+// the high-score table, or the daily leaderboard for a daily challenge.
+func finishRun() {
+	achievements.Flush()
+	if !savingEnabled {
+		return
+	}
+	run := stats.Current()
+	// An endless run was entered once already, with its time, when the boss
+	// died the first time
+	bossKill := run.BossKilled && !endless
+	score := stats.Score{
+		Depth:    run.Depth,
+		Time:     run.Time,
+		Kills:    run.TotalKills(),
+		BossKill: bossKill,
+		Seed:     rng.Seed(),
+		Date:     time.Now().Format("2006-01-02"),
+	}
+	if dailyDate != "" {
+		addDailyScore(score)
+		return
+	}
+
+	h, err := stats.LoadHighScores()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading high scores:", err)
+		return
+	}
+	if !h.Add(score) {
+		return
+	}
+	if err := stats.SaveHighScores(h); err != nil {
+		fmt.Fprintln(os.Stderr, "saving high scores:", err)
+	}
+}
+
+// highScoresScene shows the best runs, opened from the start menu.
+type highScoresScene struct {
+	scores stats.HighScores
+}
+
+func newHighScoresScene() *highScoresScene {
+	return &highScoresScene{}
+}
+
+func (s *highScoresScene) IsOverlay() bool { return true }
+
+func (s *highScoresScene) Enter() {
+	h, err := stats.LoadHighScores()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loading high scores:", err)
+	}
+	s.scores = h
+}
+
+func (s *highScoresScene) Exit() {}
+
+func (s *highScoresScene) HandleInput() {
+	if input.Cancelled() || input.Pressed(input.Confirm) || backend.Current.IsMouseButtonPressed(rl.MouseLeftButton) {
+		scenes.Pop()
+	}
+}
+
+func (s *highScoresScene) Update(dt float32) {}
+
+func (s *highScoresScene) Draw() {
+	tables := []ui.ScoreTable{
+		{Title: i18n.T("highscores.depth"), Rows: scoreRows(s.scores.Depth, func(sc stats.Score) string {
+			return i18n.T("highscores.depthIn", depthName(sc.Depth), formatDuration(sc.Time))
+		})},
+		{Title: i18n.T("highscores.bossTime"), Rows: scoreRows(s.scores.BossTime, func(sc stats.Score) string {
+			return formatDuration(sc.Time)
+		})},
+		{Title: i18n.T("highscores.kills"), Rows: scoreRows(s.scores.Kills, func(sc stats.Score) string {
+			return i18n.N("highscores.killCount", sc.Kills)
+		})},
+	}
+	ui.DrawHighScores(tables, i18n.T("highscores.hint", input.Describe(input.Back)))
+}
+
+func scoreRows(scores []stats.Score, value func(stats.Score) string) []ui.SummaryLine {
+	rows := make([]ui.SummaryLine, len(scores))
+	for i, sc := range scores {
+		rows[i] = ui.SummaryLine{Label: fmt.Sprintf("%d. %s", i+1, value(sc)), Value: sc.Date}
+	}
+	return rows
+}
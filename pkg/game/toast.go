@@ -0,0 +1,50 @@
+package game
+
+import (
+	"spooknloot/pkg/achievements"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/ui"
+)
+
+const (
+	toastDuration float32 = 4
+	toastFade     float32 = 0.3
+)
+
+// toast is the achievement currently announced in the HUD; further unlocks
+// wait in the achievements package until it is gone.
+var toast struct {
+	active bool
+	title  string
+	text   string
+	timer  float32
+}
+
+func updateToasts(dt float32) {
+	if toast.active {
+		toast.timer -= dt
+		if toast.timer > 0 {
+			return
+		}
+		toast.active = false
+	}
+	if a, ok := achievements.PopUnlocked(); ok {
+		toast.active = true
+		toast.title = i18n.T("achievement.unlocked", i18n.Or("achievement."+a.ID+".name", a.Name))
+		toast.text = i18n.Or("achievement."+a.ID+".description", a.Description)
+		toast.timer = toastDuration
+	}
+}
+
+func drawToast() {
+	if !toast.active {
+		return
+	}
+	alpha := float32(1)
+	if shown := toastDuration - toast.timer; shown < toastFade {
+		alpha = shown / toastFade
+	} else if toast.timer < toastFade {
+		alpha = toast.timer / toastFade
+	}
+	ui.DrawToast(toast.title, toast.text, alpha)
+}
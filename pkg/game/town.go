@@ -0,0 +1,102 @@
+package game
+
+import (
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/rng"
+	"spooknloot/pkg/save"
+	"spooknloot/pkg/stats"
+	"spooknloot/pkg/world"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const townMobCount = 8
+
+// townScene is the Halloween town the player starts in.
+type townScene struct {
+	spawn  rl.Vector2
+	resume *save.Run
+	// egg is the easter egg the player is standing in, so it is only
+	// reported once per visit
+	egg string
+}
+
+func newTownScene(spawn rl.Vector2) *townScene {
+	return &townScene{spawn: spawn}
+}
+
+func (s *townScene) Enter() {
+	player.ClearExternalColliders()
+	mobs.ClearExternalColliders()
+	if s.resume != nil {
+		mobs.Restore(s.resume.Mobs)
+		player.Restore(s.resume.Player)
+		s.resume = nil
+	} else {
+		player.SetPosition(s.spawn.X, s.spawn.Y)
+		mobs.ResetMobs()
+		mobs.SetRand(rng.Stream("town-mobs", 0))
+		mobs.SpawnMobs(townMobCount, "random", world.Spawn)
+	}
+	audio.PlayTrack("world")
+}
+
+func (s *townScene) Exit() {}
+
+func (s *townScene) HandleInput() {
+	handleWorldInput()
+}
+
+func (s *townScene) Update(dt float32) {
+	world.LightLamps()
+	world.LightPumpkinLamps()
+
+	if player.IsPlayerDead() {
+		player.PlayerMoving(dt)
+		if player.HasPlayerDeathAnimationFinished() {
+			scenes.Push(newGameOverScene())
+		}
+		return
+	}
+
+	mobs.SpawnMobs(townMobCount, "random", world.Spawn)
+	updateCombat(preset.MobDamage, dt)
+
+	egg, _ := world.EasterEggAt(player.PlayerHitBox)
+	if egg.Name != s.egg && egg.Name != "" {
+		stats.Emit(stats.Event{Kind: stats.EasterEggFound, Name: egg.Name})
+	}
+	s.egg = egg.Name
+
+	if s.playerAtHouseDoor() {
+		savedWorldPos = rl.NewVector2(player.PlayerDest.X, player.PlayerDest.Y)
+		scenes.Switch(newDungeonScene())
+	}
+}
+
+func (s *townScene) playerAtHouseDoor() bool {
+	return player.PlayerHitBox.X < float32(world.HouseDoorDest.X+world.HouseDoorDest.Width) &&
+		player.PlayerHitBox.X+player.PlayerHitBox.Width > float32(world.HouseDoorDest.X) &&
+		player.PlayerHitBox.Y < float32(world.HouseDoorDest.Y+world.HouseDoorDest.Height) &&
+		player.PlayerHitBox.Y+player.PlayerHitBox.Height > float32(world.HouseDoorDest.Y)
+}
+
+func (s *townScene) Draw() {
+	beginWorldDraw(worldBgColor)
+
+	world.DrawWorld()
+	world.DrawBottomLamp()
+	world.DrawDoors()
+	mobs.DrawMobs()
+	world.DrawPumpkinLamp()
+
+	player.DrawPlayerTexture()
+
+	world.DrawWheat()
+	world.DrawTopLamp()
+	world.DrawCauldron()
+
+	endWorldDraw()
+}
@@ -0,0 +1,198 @@
+// Package i18n translates the text shown to the player. Every language is a
+// catalog file in the lang directory named after its code (en.json,
+// de.json, ...) that maps keys to messages. Messages are fmt formats, so a
+// translation can reorder its arguments with %[2]s and the like.
+//
+// A key missing from the current language falls back to English and then
+// to the key itself, so a half-done translation is still playable.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fallback is the language every other one falls back to. Its catalog is
+// expected to be complete.
+const Fallback = "en"
+
+type catalog struct {
+	// Name is the language's name in itself, as the settings show it.
+	Name     string             `json:"name"`
+	Messages map[string]message `json:"messages"`
+}
+
+// message is either a plain string or, for text that depends on a count,
+// an object with one form per plural category.
+type message struct {
+	One   string
+	Other string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		m.Other = s
+		return nil
+	}
+	var forms struct {
+		One   string `json:"one"`
+		Other string `json:"other"`
+	}
+	if err := json.Unmarshal(data, &forms); err != nil {
+		return err
+	}
+	m.One = forms.One
+	m.Other = forms.Other
+	return nil
+}
+
+var (
+	catalogs = map[string]*catalog{}
+	current  = Fallback
+)
+
+// Load reads every catalog in dir. A broken file is reported but doesn't
+// stop the others from loading.
+func Load(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		code := strings.TrimSuffix(filepath.Base(path), ".json")
+		if c.Name == "" {
+			c.Name = code
+		}
+		catalogs[code] = &c
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Languages lists the codes of the loaded catalogs in alphabetical order.
+func Languages() []string {
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Name is the display name of a language, e.g. "Deutsch" for de.
+func Name(code string) string {
+	if c, ok := catalogs[code]; ok {
+		return c.Name
+	}
+	return code
+}
+
+// SetLanguage switches to the language code. Unknown codes fall back to
+// English; the result reports whether code was known.
+func SetLanguage(code string) bool {
+	if _, ok := catalogs[code]; ok {
+		current = code
+		return true
+	}
+	current = Fallback
+	return false
+}
+
+// Language is the code of the language in use.
+func Language() string {
+	return current
+}
+
+// SystemLanguage guesses the player's language from the locale environment
+// variables, e.g. "de" for LANG=de_DE.UTF-8. It returns Fallback if no
+// catalog matches.
+func SystemLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		fields := strings.FieldsFunc(os.Getenv(env), func(r rune) bool { return r == '_' || r == '.' || r == '-' })
+		if len(fields) == 0 {
+			continue
+		}
+		code := strings.ToLower(fields[0])
+		if _, ok := catalogs[code]; ok {
+			return code
+		}
+	}
+	return Fallback
+}
+
+func lookup(key string) (message, bool) {
+	for _, code := range []string{current, Fallback} {
+		if c, ok := catalogs[code]; ok {
+			if m, ok := c.Messages[key]; ok {
+				return m, true
+			}
+		}
+	}
+	return message{}, false
+}
+
+// T translates key and formats args into it. Without args the message is
+// returned as is, so it may contain a plain %.
+func T(key string, args ...any) string {
+	m, ok := lookup(key)
+	if !ok {
+		return key
+	}
+	return format(m.Other, args)
+}
+
+// Or is T for keys that may be missing from every catalog, e.g. text that
+// comes with data files. def is used in that case.
+func Or(key, def string, args ...any) string {
+	m, ok := lookup(key)
+	if !ok {
+		return format(def, args)
+	}
+	return format(m.Other, args)
+}
+
+// N translates key in the plural form for n. n is the first format
+// argument, followed by args.
+func N(key string, n int, args ...any) string {
+	m, ok := lookup(key)
+	if !ok {
+		return key
+	}
+	s := m.Other
+	if m.One != "" && pluralOne(n) {
+		s = m.One
+	}
+	return format(s, append([]any{n}, args...))
+}
+
+// pluralOne reports whether n takes the "one" form in the current language.
+// English and German agree: only exactly one is singular. Languages with
+// more plural forms need their own rule here.
+func pluralOne(n int) bool {
+	return n == 1
+}
+
+func format(s string, args []any) string {
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
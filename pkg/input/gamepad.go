@@ -0,0 +1,113 @@
+package input
+
+import (
+	"math"
+
+	"spooknloot/pkg/backend"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	// gamepad is the controller the game listens to.
+	gamepad int32 = 0
+
+	// stickDeadzone is ignored around the centre of the stick; stickPress
+	// is how far it has to be pushed to count as a direction key.
+	stickDeadzone float32 = 0.2
+	stickPress    float32 = 0.5
+)
+
+var padNames = []struct {
+	button int32
+	name   string
+}{
+	{rl.GamepadButtonRightFaceDown, "PadA"},
+	{rl.GamepadButtonRightFaceRight, "PadB"},
+	{rl.GamepadButtonRightFaceLeft, "PadX"},
+	{rl.GamepadButtonRightFaceUp, "PadY"},
+	{rl.GamepadButtonLeftFaceUp, "PadUp"},
+	{rl.GamepadButtonLeftFaceDown, "PadDown"},
+	{rl.GamepadButtonLeftFaceLeft, "PadLeft"},
+	{rl.GamepadButtonLeftFaceRight, "PadRight"},
+	{rl.GamepadButtonLeftTrigger1, "PadLB"},
+	{rl.GamepadButtonRightTrigger1, "PadRB"},
+	{rl.GamepadButtonLeftTrigger2, "PadLT"},
+	{rl.GamepadButtonRightTrigger2, "PadRT"},
+	{rl.GamepadButtonMiddleLeft, "PadSelect"},
+	{rl.GamepadButtonMiddleRight, "PadStart"},
+	{rl.GamepadButtonLeftThumb, "PadL3"},
+	{rl.GamepadButtonRightThumb, "PadR3"},
+}
+
+func Pad(button int32) Binding {
+	return Binding{Key: button, Pad: true}
+}
+
+// stick is the left stick after the deadzone, length at most 1.
+// stickWas/stickIs are the directions it was pushed past stickPress in the
+// previous and the current frame, for menu navigation.
+var (
+	stick             rl.Vector2
+	stickWas, stickIs map[Action]bool
+)
+
+// BeginFrame samples the gamepad stick. Call it once per frame before any
+// game code reads input.
+func BeginFrame() {
+	stickWas = stickIs
+	stickIs = map[Action]bool{}
+	stick = rl.Vector2{}
+
+	if !backend.Current.IsGamepadAvailable(gamepad) {
+		return
+	}
+	x := backend.Current.GamepadAxis(gamepad, rl.GamepadAxisLeftX)
+	y := backend.Current.GamepadAxis(gamepad, rl.GamepadAxisLeftY)
+	length := float32(math.Hypot(float64(x), float64(y)))
+	if length <= stickDeadzone {
+		return
+	}
+	// Rescale so movement starts at 0 just outside the deadzone and never
+	// exceeds 1, not even on the diagonals of square-gated sticks.
+	scaled := (length - stickDeadzone) / (1 - stickDeadzone)
+	if scaled > 1 {
+		scaled = 1
+	}
+	stick = rl.NewVector2(x/length*scaled, y/length*scaled)
+
+	stickIs[MoveUp] = stick.Y < -stickPress
+	stickIs[MoveDown] = stick.Y > stickPress
+	stickIs[MoveLeft] = stick.X < -stickPress
+	stickIs[MoveRight] = stick.X > stickPress
+}
+
+// Move is the direction the player wants to walk in. Keys and D-pad give
+// -1, 0 or 1 per axis, so diagonals are longer than 1; the stick gives an
+// analog vector.
+func Move() (dir rl.Vector2) {
+	if bindingDown(MoveLeft) {
+		dir.X--
+	}
+	if bindingDown(MoveRight) {
+		dir.X++
+	}
+	if bindingDown(MoveUp) {
+		dir.Y--
+	}
+	if bindingDown(MoveDown) {
+		dir.Y++
+	}
+	if dir.X != 0 || dir.Y != 0 {
+		return dir
+	}
+	return stick
+}
+
+func stickDown(a Action) bool {
+	return stickIs[a]
+}
+
+func stickPressed(a Action) bool {
+	return stickIs[a] && !stickWas[a]
+}
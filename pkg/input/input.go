@@ -0,0 +1,270 @@
+// Package input maps named actions to keys and mouse buttons. Game code
+// asks for actions instead of raw keys so players can rebind them; the
+// bindings are stored in the settings file.
+package input
+
+import (
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/settings"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+type Action string
+
+const (
+	MoveUp           Action = "MoveUp"
+	MoveDown         Action = "MoveDown"
+	MoveLeft         Action = "MoveLeft"
+	MoveRight        Action = "MoveRight"
+	Attack           Action = "Attack"
+	Dash             Action = "Dash"
+	Jump             Action = "Jump"
+	Pause            Action = "Pause"
+	Back             Action = "Back"
+	Confirm          Action = "Confirm"
+	ToggleMusic      Action = "ToggleMusic"
+	ToggleFullscreen Action = "ToggleFullscreen"
+	ToggleDebug      Action = "ToggleDebug"
+	BossRoom         Action = "BossRoom"
+	ResetBinding     Action = "ResetBinding"
+)
+
+// Actions lists every action in the order the rebinding screen shows them.
+var Actions = []Action{
+	MoveUp, MoveDown, MoveLeft, MoveRight,
+	Attack, Dash, Jump,
+	Pause, Back, Confirm,
+	ToggleMusic, ToggleFullscreen, ToggleDebug, BossRoom,
+	ResetBinding,
+}
+
+var defaults = map[Action][]Binding{
+	MoveUp:           {Key(rl.KeyW), Key(rl.KeyUp), Pad(rl.GamepadButtonLeftFaceUp)},
+	MoveDown:         {Key(rl.KeyS), Key(rl.KeyDown), Pad(rl.GamepadButtonLeftFaceDown)},
+	MoveLeft:         {Key(rl.KeyA), Key(rl.KeyLeft), Pad(rl.GamepadButtonLeftFaceLeft)},
+	MoveRight:        {Key(rl.KeyD), Key(rl.KeyRight), Pad(rl.GamepadButtonLeftFaceRight)},
+	Attack:           {Mouse(rl.MouseButtonLeft), Pad(rl.GamepadButtonRightFaceLeft)},
+	Dash:             {Key(rl.KeyLeftShift), Key(rl.KeyRightShift), Pad(rl.GamepadButtonRightFaceRight), Pad(rl.GamepadButtonRightTrigger1)},
+	Jump:             {Key(rl.KeySpace), Pad(rl.GamepadButtonRightFaceDown)},
+	Pause:            {Key(rl.KeyEscape), Pad(rl.GamepadButtonMiddleRight)},
+	Back:             {Key(rl.KeyEscape), Pad(rl.GamepadButtonRightFaceRight)},
+	Confirm:          {Key(rl.KeyEnter), Pad(rl.GamepadButtonRightFaceDown)},
+	ToggleMusic:      {Key(rl.KeyF7)},
+	ToggleFullscreen: {Key(rl.KeyF10)},
+	ToggleDebug:      {Key(rl.KeyF3)},
+	BossRoom:         {Key(rl.KeyB)},
+	ResetBinding:     {Key(rl.KeyBackspace), Key(rl.KeyDelete)},
+}
+
+var bindings = map[Action][]Binding{}
+
+// fixed holds the bindings a replay was recorded with; while set, Load
+// uses them instead of the settings file and rebinding isn't saved.
+var fixed map[string][]string
+
+// UseFixed makes Load apply saved instead of the player's bindings, so a
+// replay's raw keys map to the same actions it was recorded with.
+func UseFixed(saved map[string][]string) {
+	if saved == nil {
+		saved = map[string][]string{}
+	}
+	fixed = saved
+}
+
+func init() {
+	ResetAll()
+}
+
+// Load applies the bindings stored in settings.Current on top of the
+// defaults. Unknown actions and key names are ignored.
+func Load() {
+	ResetAll()
+	saved := settings.Current.Bindings
+	if fixed != nil {
+		saved = fixed
+	}
+	for name, keys := range saved {
+		a := Action(name)
+		if _, ok := defaults[a]; !ok {
+			continue
+		}
+		var list []Binding
+		for _, k := range keys {
+			if b, ok := ParseBinding(k); ok {
+				list = append(list, b)
+			}
+		}
+		if len(list) > 0 {
+			bindings[a] = list
+		}
+	}
+}
+
+// ResetAll restores the default bindings without saving them.
+func ResetAll() {
+	for a, list := range defaults {
+		bindings[a] = append([]Binding(nil), list...)
+	}
+}
+
+func Bindings(a Action) []Binding {
+	return bindings[a]
+}
+
+// Label is the human readable name of an action in the current language.
+func Label(a Action) string {
+	return i18n.T("action." + string(a))
+}
+
+// Describe lists the bindings of an action for display, e.g. "W / Up".
+func Describe(a Action) string {
+	s := ""
+	for i, b := range bindings[a] {
+		if i > 0 {
+			s += " / "
+		}
+		s += b.String()
+	}
+	return s
+}
+
+// Down reports whether any binding of the action is held. The movement
+// actions also count the left stick.
+func Down(a Action) bool {
+	return stickDown(a) || bindingDown(a)
+}
+
+func bindingDown(a Action) bool {
+	for _, b := range bindings[a] {
+		if b.Pad {
+			if backend.Current.IsGamepadAvailable(gamepad) && backend.Current.IsGamepadButtonDown(gamepad, b.Key) {
+				return true
+			}
+		} else if b.Mouse {
+			if backend.Current.IsMouseButtonDown(rl.MouseButton(b.Key)) {
+				return true
+			}
+		} else if backend.Current.IsKeyDown(b.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pressed reports whether any binding of the action went down this frame.
+func Pressed(a Action) bool {
+	if stickPressed(a) {
+		return true
+	}
+	for _, b := range bindings[a] {
+		if b.Pad {
+			if backend.Current.IsGamepadAvailable(gamepad) && backend.Current.IsGamepadButtonPressed(gamepad, b.Key) {
+				return true
+			}
+		} else if b.Mouse {
+			if backend.Current.IsMouseButtonPressed(rl.MouseButton(b.Key)) {
+				return true
+			}
+		} else if backend.Current.IsKeyPressed(b.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnyPressed returns the first bindable key, mouse or gamepad button
+// pressed this frame, for the rebinding screen.
+func AnyPressed() (Binding, bool) {
+	for _, k := range keyNames {
+		if backend.Current.IsKeyPressed(k.key) {
+			return Key(k.key), true
+		}
+	}
+	for _, m := range mouseNames {
+		if backend.Current.IsMouseButtonPressed(m.button) {
+			return Mouse(m.button), true
+		}
+	}
+	if backend.Current.IsGamepadAvailable(gamepad) {
+		for _, p := range padNames {
+			if backend.Current.IsGamepadButtonPressed(gamepad, p.button) {
+				return Pad(p.button), true
+			}
+		}
+	}
+	return Binding{}, false
+}
+
+// Rebind makes b the binding of a on its device: a key or mouse button
+// replaces the keyboard and mouse bindings, a gamepad button the gamepad
+// ones. Other actions that used b lose it so one key never triggers two
+// actions, unless it was their last one.
+func Rebind(a Action, b Binding) error {
+	for other, list := range bindings {
+		if other == a {
+			continue
+		}
+		kept := make([]Binding, 0, len(list))
+		for _, x := range list {
+			if x != b {
+				kept = append(kept, x)
+			}
+		}
+		if len(kept) > 0 {
+			bindings[other] = kept
+		}
+	}
+	list := []Binding{b}
+	for _, x := range bindings[a] {
+		if x.Pad != b.Pad {
+			list = append(list, x)
+		}
+	}
+	bindings[a] = list
+	return store()
+}
+
+// Reset restores the default bindings of a single action.
+func Reset(a Action) error {
+	bindings[a] = append([]Binding(nil), defaults[a]...)
+	return store()
+}
+
+// Cancelled reports whether the player wants to leave the current menu.
+func Cancelled() bool {
+	return Pressed(Back) || Pressed(Pause)
+}
+
+// store writes every binding that differs from its default to the
+// settings file.
+func store() error {
+	if fixed != nil {
+		return nil
+	}
+	saved := map[string][]string{}
+	for a, list := range bindings {
+		if equal(list, defaults[a]) {
+			continue
+		}
+		names := make([]string, len(list))
+		for i, b := range list {
+			names[i] = b.String()
+		}
+		saved[string(a)] = names
+	}
+	return settings.Change(func(s *settings.Settings) { s.Bindings = saved })
+}
+
+func equal(a, b []Binding) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package input
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// keyNames are the names keys are stored under in the settings file and
+// shown on the rebinding screen. Only keys listed here can be bound.
+var keyNames = []struct {
+	key  int32
+	name string
+}{
+	{rl.KeyA, "A"}, {rl.KeyB, "B"}, {rl.KeyC, "C"}, {rl.KeyD, "D"}, {rl.KeyE, "E"},
+	{rl.KeyF, "F"}, {rl.KeyG, "G"}, {rl.KeyH, "H"}, {rl.KeyI, "I"}, {rl.KeyJ, "J"},
+	{rl.KeyK, "K"}, {rl.KeyL, "L"}, {rl.KeyM, "M"}, {rl.KeyN, "N"}, {rl.KeyO, "O"},
+	{rl.KeyP, "P"}, {rl.KeyQ, "Q"}, {rl.KeyR, "R"}, {rl.KeyS, "S"}, {rl.KeyT, "T"},
+	{rl.KeyU, "U"}, {rl.KeyV, "V"}, {rl.KeyW, "W"}, {rl.KeyX, "X"}, {rl.KeyY, "Y"},
+	{rl.KeyZ, "Z"},
+	{rl.KeyZero, "0"}, {rl.KeyOne, "1"}, {rl.KeyTwo, "2"}, {rl.KeyThree, "3"}, {rl.KeyFour, "4"},
+	{rl.KeyFive, "5"}, {rl.KeySix, "6"}, {rl.KeySeven, "7"}, {rl.KeyEight, "8"}, {rl.KeyNine, "9"},
+	{rl.KeyUp, "Up"}, {rl.KeyDown, "Down"}, {rl.KeyLeft, "Left"}, {rl.KeyRight, "Right"},
+	{rl.KeySpace, "Space"}, {rl.KeyEnter, "Enter"}, {rl.KeyEscape, "Escape"}, {rl.KeyTab, "Tab"},
+	{rl.KeyBackspace, "Backspace"}, {rl.KeyInsert, "Insert"}, {rl.KeyDelete, "Delete"},
+	{rl.KeyHome, "Home"}, {rl.KeyEnd, "End"}, {rl.KeyPageUp, "PageUp"}, {rl.KeyPageDown, "PageDown"},
+	{rl.KeyLeftShift, "LeftShift"}, {rl.KeyRightShift, "RightShift"},
+	{rl.KeyLeftControl, "LeftCtrl"}, {rl.KeyRightControl, "RightCtrl"},
+	{rl.KeyLeftAlt, "LeftAlt"}, {rl.KeyRightAlt, "RightAlt"},
+	{rl.KeyF1, "F1"}, {rl.KeyF2, "F2"}, {rl.KeyF3, "F3"}, {rl.KeyF4, "F4"},
+	{rl.KeyF5, "F5"}, {rl.KeyF6, "F6"}, {rl.KeyF7, "F7"}, {rl.KeyF8, "F8"},
+	{rl.KeyF9, "F9"}, {rl.KeyF10, "F10"}, {rl.KeyF11, "F11"}, {rl.KeyF12, "F12"},
+	{rl.KeyComma, "Comma"}, {rl.KeyPeriod, "Period"}, {rl.KeyMinus, "Minus"}, {rl.KeyEqual, "Equal"},
+	{rl.KeySemicolon, "Semicolon"}, {rl.KeyApostrophe, "Apostrophe"}, {rl.KeySlash, "Slash"},
+	{rl.KeyBackSlash, "Backslash"}, {rl.KeyLeftBracket, "LeftBracket"}, {rl.KeyRightBracket, "RightBracket"},
+	{rl.KeyGrave, "Grave"},
+	{rl.KeyKp0, "Num0"}, {rl.KeyKp1, "Num1"}, {rl.KeyKp2, "Num2"}, {rl.KeyKp3, "Num3"}, {rl.KeyKp4, "Num4"},
+	{rl.KeyKp5, "Num5"}, {rl.KeyKp6, "Num6"}, {rl.KeyKp7, "Num7"}, {rl.KeyKp8, "Num8"}, {rl.KeyKp9, "Num9"},
+	{rl.KeyKpEnter, "NumEnter"},
+}
+
+var mouseNames = []struct {
+	button rl.MouseButton
+	name   string
+}{
+	{rl.MouseButtonLeft, "MouseLeft"},
+	{rl.MouseButtonRight, "MouseRight"},
+	{rl.MouseButtonMiddle, "MouseMiddle"},
+	{rl.MouseButtonSide, "MouseSide"},
+	{rl.MouseButtonExtra, "MouseExtra"},
+}
+
+// Binding is one key, mouse button or gamepad button that triggers an
+// action.
+type Binding struct {
+	Key   int32
+	Mouse bool // Key holds an rl.MouseButton
+	Pad   bool // Key holds a gamepad button
+}
+
+func Key(key int32) Binding {
+	return Binding{Key: key}
+}
+
+func Mouse(button rl.MouseButton) Binding {
+	return Binding{Key: int32(button), Mouse: true}
+}
+
+// String is the name the binding is saved and displayed as.
+func (b Binding) String() string {
+	if b.Pad {
+		for _, p := range padNames {
+			if p.button == b.Key {
+				return p.name
+			}
+		}
+		return "Pad?"
+	}
+	if b.Mouse {
+		for _, m := range mouseNames {
+			if int32(m.button) == b.Key {
+				return m.name
+			}
+		}
+		return "Mouse?"
+	}
+	for _, k := range keyNames {
+		if k.key == b.Key {
+			return k.name
+		}
+	}
+	return "?"
+}
+
+// ParseBinding turns a saved name back into a binding.
+func ParseBinding(name string) (Binding, bool) {
+	for _, k := range keyNames {
+		if k.name == name {
+			return Key(k.key), true
+		}
+	}
+	for _, m := range mouseNames {
+		if m.name == name {
+			return Mouse(m.button), true
+		}
+	}
+	for _, p := range padNames {
+		if p.name == name {
+			return Pad(p.button), true
+		}
+	}
+	return Binding{}, false
+}
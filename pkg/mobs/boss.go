@@ -1,6 +1,8 @@
 package mobs
 
 import (
+	"spooknloot/pkg/backend"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -10,23 +12,26 @@ var (
 )
 
 func InitBoss() {
-	bossSprite = rl.LoadTexture("assets/mobs/boss.png")
+	bossSprite = backend.Current.LoadTexture("assets/mobs/boss.png")
 }
 
 func SpawnBossAtPosition(p rl.Vector2) int {
 	newMob := Mob{
+		Type:         "boss",
 		Sprite:       bossSprite,
 		Src:          rl.NewRectangle(0, 0, 64, 64),
+		OldX:         p.X,
+		OldY:         p.Y,
 		Dest:         rl.NewRectangle(p.X, p.Y, 64, 64),
 		Dir:          int(DirIdleDown),
 		Frame:        0,
 		HitBox:       rl.NewRectangle(0, 0, 32, 32),
-		FrameCount:   0,
+		AnimTimer:    0,
 		LastAttack:   0,
 		IsAttacking:  false,
 		AttackTimer:  0,
-		MaxHealth:    100.0,
-		Health:       100.0,
+		MaxHealth:    bossHealth,
+		Health:       bossHealth,
 		HealthbarDir: 0,
 		IsDead:       false,
 		DeathTimer:   0,
@@ -4,56 +4,78 @@ import (
 	"math"
 	"math/rand"
 
-	"spooknloot/pkg/boss"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/stats"
 	"spooknloot/pkg/world"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 type Mob struct {
-	Sprite       rl.Texture2D
+	Type         string
+	Sprite       rl.Texture2D `json:"-"`
 	OldX, OldY   float32
 	Src          rl.Rectangle
 	Dest         rl.Rectangle
 	Dir          int
 	Frame        int
 	HitBox       rl.Rectangle
-	FrameCount   int
-	LastAttack   int
+	AnimTimer    float32
+	LastAttack   float32
 	IsAttacking  bool
-	AttackTimer  int
+	AttackTimer  float32
 	MaxHealth    float32
 	Health       float32
 	HealthbarDir int
 	IsDead       bool
-	DeathTimer   int
+	DeathTimer   float32
 	Damage       bool
-	DamageTimer  int
+	DamageTimer  float32
 }
 
 var (
-	deathDuration     int     = 120
+	// spawnRand picks spawn tiles and mob types, see SetRand.
+	spawnRand = rand.New(rand.NewSource(1))
+
+	// Set per run by SetStrength.
+	mobHealth  float32 = 5
+	bossHealth float32 = 100
+	bossArmor  float32 = 0.6
+
+	// Durations in seconds, speeds in pixels per second.
+	deathDuration     float32 = 2
 	attackRange       float32 = 25
-	attackDuration    int     = 20
-	attackCooldown    int     = 60
+	attackDuration    float32 = 20.0 / 60
+	attackHitStart    float32 = 3.0 / 60
+	attackHitEnd      float32 = 6.0 / 60
+	attackCooldown    float32 = 1
+	damageDuration    float32 = 0.1
+	animFrameTime     float32 = 10.0 / 60
+	mobSpeed          float32 = 36
+	bossSpeed         float32 = 54
 	mobs              []Mob
 	mobTexture        rl.Texture2D
 	batSprite         rl.Texture2D
-	globalFrameCount  int
+	globalTime        float32
+	renderAlpha       float32 = 1
 	externalColliders []rl.Rectangle
 	skeletonSprite1   rl.Texture2D
 	skeletonSprite2   rl.Texture2D
 	skeletonSprite3   rl.Texture2D
 	zombieSprite      rl.Texture2D
 	defaultMobTypes   = []string{"bat", "skeleton1", "skeleton2", "skeleton3", "zombie"}
+	// spawnTypes are the types "random" picks from, see SetSpawnTypes.
+	spawnTypes = defaultMobTypes
 
 	flowField          [][]rl.Vector2
 	flowBlocked        [][]bool
 	flowW, flowH       int
 	flowTileSize       int
 	flowDirty          bool
-	flowRecalcInterval int = 6
-	lastFlowCalcFrame  int
+	flowRecalcInterval float32 = 0.1
+	// flowTarget is the cell the flow field leads to.
+	flowTargetX, flowTargetY int
+	lastFlowCalcTime         float32
 )
 
 type Direction int
@@ -82,61 +104,89 @@ const (
 )
 
 func InitMobs() {
-	batSprite = rl.LoadTexture("assets/mobs/bat-spritesheet.png")
-	skeletonSprite1 = rl.LoadTexture("assets/mobs/skeleton_1.png")
-	skeletonSprite2 = rl.LoadTexture("assets/mobs/skeleton_2.png")
-	zombieSprite = rl.LoadTexture("assets/mobs/zombie.png")
-	skeletonSprite3 = rl.LoadTexture("assets/mobs/skeleton_3.png")
+	batSprite = backend.Current.LoadTexture("assets/mobs/bat-spritesheet.png")
+	skeletonSprite1 = backend.Current.LoadTexture("assets/mobs/skeleton_1.png")
+	skeletonSprite2 = backend.Current.LoadTexture("assets/mobs/skeleton_2.png")
+	zombieSprite = backend.Current.LoadTexture("assets/mobs/zombie.png")
+	skeletonSprite3 = backend.Current.LoadTexture("assets/mobs/skeleton_3.png")
 	InitBoss()
 }
 
+// SetRand sets the generator used by SpawnMobs and SpawnMobsAtPositions so a
+// seeded run spawns the same mobs.
+func SetRand(r *rand.Rand) {
+	spawnRand = r
+}
+
+// SetStrength sets the health new mobs and the boss spawn with and the share
+// of the player's damage the boss takes, see package difficulty.
+func SetStrength(mob, boss, armor float32) {
+	mobHealth = mob
+	bossHealth = boss
+	bossArmor = armor
+}
+
+// SetSpawnTypes limits the mob types "random" spawns pick from. nil (or an
+// empty list) allows every type again.
+func SetSpawnTypes(types []string) {
+	if len(types) == 0 {
+		types = defaultMobTypes
+	}
+	spawnTypes = types
+}
+
+func spriteForType(t string) rl.Texture2D {
+	switch t {
+	case "bat":
+		return batSprite
+	case "skeleton1":
+		return skeletonSprite1
+	case "skeleton2":
+		return skeletonSprite2
+	case "skeleton3":
+		return skeletonSprite3
+	case "zombie":
+		return zombieSprite
+	case "boss":
+		return bossSprite
+	default:
+		return skeletonSprite1
+	}
+}
+
 func SpawnMobs(amount int, mobType string, tiles []world.Tile) int {
 	if len(tiles) == 0 || amount <= 0 {
 		return len(mobs)
 	}
 
-	selectMobTexture := func(t string) rl.Texture2D {
-		switch t {
-		case "bat":
-			return batSprite
-		case "skeleton1":
-			return skeletonSprite1
-		case "skeleton2":
-			return skeletonSprite2
-		case "skeleton3":
-			return skeletonSprite3
-		case "zombie":
-			return zombieSprite
-		default:
-			return skeletonSprite1
-		}
-	}
-
 	for len(mobs) < amount {
-		randomIndex := rand.Intn(len(tiles))
+		randomIndex := spawnRand.Intn(len(tiles))
 		selectedTile := tiles[randomIndex]
 		x := float32(selectedTile.X * world.WorldMap.TileSize)
 		y := float32(selectedTile.Y * world.WorldMap.TileSize)
 
 		chosenType := mobType
 		if mobType == "random" {
-			chosenType = defaultMobTypes[rand.Intn(len(defaultMobTypes))]
+			chosenType = spawnTypes[spawnRand.Intn(len(spawnTypes))]
 		}
-		sprite := selectMobTexture(chosenType)
+		sprite := spriteForType(chosenType)
 
 		newMob := Mob{
+			Type:         chosenType,
 			Sprite:       sprite,
 			Src:          rl.NewRectangle(0, 0, 16, 16),
+			OldX:         x,
+			OldY:         y,
 			Dest:         rl.NewRectangle(x, y, 16, 16),
 			Dir:          int(DirIdleDown),
 			Frame:        0,
 			HitBox:       rl.NewRectangle(0, 0, 8, 8),
-			FrameCount:   0,
+			AnimTimer:    0,
 			LastAttack:   0,
 			IsAttacking:  false,
 			AttackTimer:  0,
-			MaxHealth:    5.0,
-			Health:       5.0,
+			MaxHealth:    mobHealth,
+			Health:       mobHealth,
 			HealthbarDir: 0,
 			IsDead:       false,
 			DeathTimer:   0,
@@ -154,43 +204,29 @@ func SpawnMobsAtPositions(positions []rl.Vector2, mobType string) int {
 		return len(mobs)
 	}
 
-	selectMobTexture := func(t string) rl.Texture2D {
-		switch t {
-		case "bat":
-			return batSprite
-		case "skeleton1":
-			return skeletonSprite1
-		case "skeleton2":
-			return skeletonSprite2
-		case "skeleton3":
-			return skeletonSprite3
-		case "zombie":
-			return zombieSprite
-		default:
-			return skeletonSprite1
-		}
-	}
-
 	for _, p := range positions {
 		chosenType := mobType
 		if mobType == "random" {
-			chosenType = defaultMobTypes[rand.Intn(len(defaultMobTypes))]
+			chosenType = spawnTypes[spawnRand.Intn(len(spawnTypes))]
 		}
-		sprite := selectMobTexture(chosenType)
+		sprite := spriteForType(chosenType)
 
 		newMob := Mob{
+			Type:         chosenType,
 			Sprite:       sprite,
 			Src:          rl.NewRectangle(0, 0, 16, 16),
+			OldX:         p.X,
+			OldY:         p.Y,
 			Dest:         rl.NewRectangle(p.X, p.Y, 16, 16),
 			Dir:          int(DirIdleDown),
 			Frame:        0,
 			HitBox:       rl.NewRectangle(0, 0, 8, 8),
-			FrameCount:   0,
+			AnimTimer:    0,
 			LastAttack:   0,
 			IsAttacking:  false,
 			AttackTimer:  0,
-			MaxHealth:    5.0,
-			Health:       5.0,
+			MaxHealth:    mobHealth,
+			Health:       mobHealth,
 			HealthbarDir: 0,
 			IsDead:       false,
 			DeathTimer:   0,
@@ -202,14 +238,15 @@ func SpawnMobsAtPositions(positions []rl.Vector2, mobType string) int {
 	return len(mobs)
 }
 
-func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
-	globalFrameCount++
+// MobMoving advances every mob by one simulation tick of dt seconds.
+func MobMoving(playerPos rl.Vector2, attackPlayerFunc func(), dt float32) {
+	globalTime += dt
 
 	if len(externalColliders) > 0 {
 		ensureFlowGrid()
-		if flowDirty || globalFrameCount-lastFlowCalcFrame >= flowRecalcInterval {
+		if flowDirty || globalTime-lastFlowCalcTime >= flowRecalcInterval {
 			buildFlowFieldTowards(playerPos)
-			lastFlowCalcFrame = globalFrameCount
+			lastFlowCalcTime = globalTime
 			flowDirty = false
 		}
 	}
@@ -223,7 +260,9 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 			continue
 		}
 
-		if mobs[i].FrameCount%10 == 1 {
+		mobs[i].AnimTimer += dt
+		if mobs[i].AnimTimer >= animFrameTime {
+			mobs[i].AnimTimer -= animFrameTime
 			mobs[i].Frame++
 		}
 
@@ -251,11 +290,9 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 			mobs[i].Frame = 0
 		}
 
-		mobs[i].FrameCount++
-
 		if mobs[i].IsDead {
 			mobs[i].Dir = int(DirDeadDown)
-			mobs[i].DeathTimer++
+			mobs[i].DeathTimer += dt
 			if mobs[i].DeathTimer >= deathDuration {
 				mobs[i].IsDead = false
 				mobs[i].DeathTimer = 0
@@ -263,8 +300,9 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 		}
 
 		if mobs[i].DamageTimer > 0 {
-			mobs[i].DamageTimer--
-			if mobs[i].DamageTimer == 0 {
+			mobs[i].DamageTimer -= dt
+			if mobs[i].DamageTimer <= 0 {
+				mobs[i].DamageTimer = 0
 				mobs[i].Damage = false
 			}
 		}
@@ -282,17 +320,18 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 				if i == bossIndex {
 					currentAttackRange = 48
 				}
-				if dist <= currentAttackRange && globalFrameCount-mobs[i].LastAttack >= attackCooldown && !mobs[i].IsAttacking {
-					mobs[i].LastAttack = globalFrameCount
+				if dist <= currentAttackRange && globalTime-mobs[i].LastAttack >= attackCooldown && !mobs[i].IsAttacking {
+					mobs[i].LastAttack = globalTime
 					mobs[i].IsAttacking = true
 					mobs[i].AttackTimer = attackDuration
 				}
 
 				if mobs[i].IsAttacking {
 					mobs[i].Dir = int(DirAttackDown)
-					mobs[i].AttackTimer--
+					mobs[i].AttackTimer -= dt
 
-					if mobs[i].AttackTimer <= attackDuration-3 && mobs[i].AttackTimer > attackDuration-6 {
+					elapsed := attackDuration - mobs[i].AttackTimer
+					if elapsed >= attackHitStart && elapsed < attackHitEnd {
 						attackPlayerFunc()
 					}
 					if mobs[i].AttackTimer <= 0 {
@@ -300,7 +339,7 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 					}
 				}
 
-				if !mobs[i].IsAttacking && dist < 180 && dist > 8 {
+				if !mobs[i].IsAttacking && dist < 180 && dist > 8 && !cutOff(mobCenterX, mobCenterY) {
 
 					directionX := playerPos.X - mobCenterX
 					directionY := playerPos.Y - mobCenterY
@@ -343,17 +382,13 @@ func MobMoving(playerPos rl.Vector2, attackPlayerFunc func()) {
 						}
 					}
 
-					moveSpeed := float32(0.6)
+					moveSpeed := mobSpeed
 					if i == bossIndex {
-						moveSpeed = 0.9
+						moveSpeed = bossSpeed
 					}
 
-					stepX := directionX * (moveSpeed / 2)
-					stepY := directionY * (moveSpeed / 2)
-					mobs[i].Dest.X += stepX
-					mobs[i].Dest.Y += stepY
-					mobs[i].Dest.X += stepX
-					mobs[i].Dest.Y += stepY
+					mobs[i].Dest.X += directionX * moveSpeed * dt
+					mobs[i].Dest.Y += directionY * moveSpeed * dt
 				}
 			}
 		}
@@ -444,16 +479,6 @@ func mobCollidesAny(mobIndex int) bool {
 		return true
 	}
 
-	if len(boss.Out) > 0 {
-		bt := make([]world.Tile, 0, len(boss.Out))
-		for _, t := range boss.Out {
-			bt = append(bt, world.Tile{X: t.X, Y: t.Y})
-		}
-		if mobHitboxCollidesWithTiles(mobs[mobIndex].HitBox, bt) {
-			return true
-		}
-	}
-
 	return false
 }
 
@@ -498,12 +523,13 @@ func DrawMobsHealthBar(mobIndex int) {
 		return
 	}
 
-	maxBarWidth := mobs[mobIndex].Dest.Width
+	dest := interpolatedDest(mobs[mobIndex])
+	maxBarWidth := dest.Width
 	barHeight := float32(1)
 	padding := float32(0)
 
-	barX := mobs[mobIndex].Dest.X + padding
-	barY := mobs[mobIndex].Dest.Y - barHeight - 2
+	barX := dest.X + padding
+	barY := dest.Y - barHeight - 2
 
 	healthPercent := mobs[mobIndex].Health / mobs[mobIndex].MaxHealth
 	if healthPercent < 0 {
@@ -547,6 +573,16 @@ func IsMobAlive() bool {
 	return false
 }
 
+// MobCount is how many mobs the level has had, dead ones included; the
+// next spawned mob gets this index.
+func MobCount() int {
+	return len(mobs)
+}
+
+func IsMobAliveByIndex(index int) bool {
+	return index >= 0 && index < len(mobs) && mobs[index].Health > 0 && !mobs[index].IsDead
+}
+
 func GetMobPositionByIndex(index int) rl.Vector2 {
 	if index < 0 || index >= len(mobs) || mobs[index].Health <= 0 || mobs[index].IsDead {
 		return rl.NewVector2(0, 0)
@@ -565,6 +601,19 @@ func GetMobHitboxCenterByIndex(index int) rl.Vector2 {
 	return rl.NewVector2(cx, cy)
 }
 
+// GetMobHitboxByIndex is the hitbox of a living mob, empty for dead ones.
+func GetMobHitboxByIndex(index int) rl.Rectangle {
+	if index < 0 || index >= len(mobs) || mobs[index].Health <= 0 || mobs[index].IsDead {
+		return rl.Rectangle{}
+	}
+	return mobs[index].HitBox
+}
+
+// IsMobFlyingByIndex reports whether the mob flies over floor traps.
+func IsMobFlyingByIndex(index int) bool {
+	return index >= 0 && index < len(mobs) && mobs[index].Type == "bat"
+}
+
 func GetClosestMobIndex(playerPos rl.Vector2) int {
 	closestIndex := -1
 	closestIndexDistance := float32(999999)
@@ -585,10 +634,28 @@ func GetClosestMobIndex(playerPos rl.Vector2) int {
 	return closestIndex
 }
 
+// SetInterpolation sets how far rendering is between the previous and the
+// current simulation tick (0..1).
+func SetInterpolation(alpha float32) {
+	if alpha < 0 {
+		alpha = 0
+	} else if alpha > 1 {
+		alpha = 1
+	}
+	renderAlpha = alpha
+}
+
+func interpolatedDest(m Mob) rl.Rectangle {
+	dest := m.Dest
+	dest.X = m.OldX + (m.Dest.X-m.OldX)*renderAlpha
+	dest.Y = m.OldY + (m.Dest.Y-m.OldY)*renderAlpha
+	return dest
+}
+
 func DrawMobs() {
 	for i := range mobs {
 		if mobs[i].Health > 0 || mobs[i].IsDead {
-			rl.DrawTexturePro(mobs[i].Sprite, mobs[i].Src, mobs[i].Dest, rl.NewVector2(0, 0), 0, rl.White)
+			rl.DrawTexturePro(mobs[i].Sprite, mobs[i].Src, interpolatedDest(mobs[i]), rl.NewVector2(0, 0), 0, rl.White)
 			if mobs[i].Health > 0 && !mobs[i].IsDead && i != bossIndex {
 				DrawMobsHealthBar(i)
 			}
@@ -596,13 +663,24 @@ func DrawMobs() {
 	}
 }
 
+// DamageMob hurts a mob on the player's behalf; the damage and a kill count
+// towards the run's stats.
 func DamageMob(mobIndex int, damage float32) {
+	damageMob(mobIndex, damage, true)
+}
+
+// HurtMob hurts a mob without crediting the player, e.g. a trap.
+func HurtMob(mobIndex int, damage float32) {
+	damageMob(mobIndex, damage, false)
+}
+
+func damageMob(mobIndex int, damage float32, byPlayer bool) {
 	if mobIndex < 0 || mobIndex >= len(mobs) {
 		return
 	}
 
 	mobs[mobIndex].Damage = true
-	mobs[mobIndex].DamageTimer = 6 // ~0.2s at 60 FPS
+	mobs[mobIndex].DamageTimer = damageDuration
 	switch Direction(mobs[mobIndex].Dir) {
 	case DirMoveUp, DirAttackUp:
 		mobs[mobIndex].Dir = int(DirDamageUp)
@@ -618,16 +696,23 @@ func DamageMob(mobIndex int, damage float32) {
 	wasAlive := mobs[mobIndex].Health > 0
 
 	if mobIndex == bossIndex {
-		damage *= 0.6
+		damage *= bossArmor
 	}
+	before := mobs[mobIndex].Health
 	mobs[mobIndex].Health -= damage
 	if mobs[mobIndex].Health < 0 {
 		mobs[mobIndex].Health = 0
 	}
+	if byPlayer {
+		stats.Emit(stats.Event{Kind: stats.MobDamaged, MobType: mobs[mobIndex].Type, Amount: before - mobs[mobIndex].Health})
+	}
 
 	if wasAlive && mobs[mobIndex].Health <= 0 {
 		mobs[mobIndex].IsDead = true
 		mobs[mobIndex].DeathTimer = 0
+		if byPlayer {
+			stats.Emit(stats.Event{Kind: stats.MobKilled, MobType: mobs[mobIndex].Type})
+		}
 	}
 
 	healthPercentage := mobs[mobIndex].Health / mobs[mobIndex].MaxHealth
@@ -646,12 +731,13 @@ func DamageMob(mobIndex int, damage float32) {
 
 func ResetMobs() {
 	mobs = []Mob{}
-	globalFrameCount = 0
+	globalTime = 0
+	lastFlowCalcTime = 0
 	bossIndex = -1
 }
 
 func UnloadMobsTexture() {
-	rl.UnloadTexture(mobTexture)
+	backend.Current.UnloadTexture(mobTexture)
 }
 
 // -----------------------
@@ -663,8 +749,8 @@ func ensureFlowGrid() {
 	if len(externalColliders) == 0 {
 		return
 	}
-	// Dungeon uses fixed sizes from dungeon package
-	// Infer grid bounds from colliders’ max extents and tile size (16)
+	// Dungeon levels grow with depth, so the grid bounds are inferred from
+	// the colliders' max extents and tile size (16) for every new level
 	flowTileSize = 16
 	maxX, maxY := 0, 0
 	for i := 0; i < len(externalColliders); i++ {
@@ -748,6 +834,7 @@ func buildFlowFieldTowards(target rl.Vector2) {
 	if ty >= flowH {
 		ty = flowH - 1
 	}
+	flowTargetX, flowTargetY = tx, ty
 	// Allow standing on a floor cell; avoid marking target as blocked
 	// If target happens to be inside a collider cell, we still seed there to pull vectors inward.
 
@@ -823,6 +910,21 @@ func buildFlowFieldTowards(target rl.Vector2) {
 	}
 }
 
+// cutOff reports whether a mob at world position (x,y) stands on open
+// ground with no way to the player in the flow field, e.g. behind a locked
+// door. Such mobs wait instead of walking through the walls.
+func cutOff(x, y float32) bool {
+	if len(externalColliders) == 0 || flowBlocked == nil || flowTileSize == 0 {
+		return false
+	}
+	gx, gy := int(x)/flowTileSize, int(y)/flowTileSize
+	if gx < 0 || gy < 0 || gx >= flowW || gy >= flowH || flowBlocked[gy][gx] || (gx == flowTargetX && gy == flowTargetY) {
+		return false
+	}
+	_, _, ok := sampleFlowAt(x, y)
+	return !ok
+}
+
 // sampleFlowAt returns a direction vector from the flow field at world position (x,y).
 func sampleFlowAt(x, y float32) (fx, fy float32, ok bool) {
 	if flowField == nil || flowW == 0 || flowH == 0 || flowTileSize == 0 {
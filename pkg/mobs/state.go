@@ -0,0 +1,42 @@
+package mobs
+
+import (
+	"spooknloot/pkg/rng"
+)
+
+// State is everything needed to put the mobs of a level back exactly as
+// they were.
+type State struct {
+	Mobs      []Mob
+	BossIndex int
+	Time      float32
+	Rand      *rng.StreamState `json:",omitempty"`
+}
+
+func Snapshot() State {
+	s := State{
+		Mobs:      append([]Mob(nil), mobs...),
+		BossIndex: bossIndex,
+		Time:      globalTime,
+	}
+	if r, ok := rng.State(spawnRand); ok {
+		s.Rand = &r
+	}
+	return s
+}
+
+// Restore replaces the current mobs with a saved set. Textures are looked
+// up again from each mob's type.
+func Restore(s State) {
+	ResetMobs()
+	mobs = append([]Mob(nil), s.Mobs...)
+	for i := range mobs {
+		mobs[i].Sprite = spriteForType(mobs[i].Type)
+	}
+	bossIndex = s.BossIndex
+	globalTime = s.Time
+	flowDirty = true
+	if s.Rand != nil {
+		spawnRand = rng.Resume(*s.Rand)
+	}
+}
@@ -0,0 +1,51 @@
+package player
+
+// Buff is a temporary bonus, e.g. from a dungeon shrine. Amount is added to
+// the factor of its Kind, so 0.5 is +50%.
+type Buff struct {
+	// Kind is "damage", "speed" or "regen".
+	Kind   string  `json:"kind"`
+	Amount float32 `json:"amount"`
+	// Time is how many seconds the buff has left.
+	Time float32 `json:"time"`
+}
+
+var buffs []Buff
+
+// AddBuff grants a buff for duration seconds. A buff of the same kind is
+// replaced, so shrines don't stack.
+func AddBuff(kind string, amount, duration float32) {
+	for i := range buffs {
+		if buffs[i].Kind == kind {
+			buffs[i] = Buff{Kind: kind, Amount: amount, Time: duration}
+			return
+		}
+	}
+	buffs = append(buffs, Buff{Kind: kind, Amount: amount, Time: duration})
+}
+
+// BuffFactor is 1 plus the amount of the active buff of kind.
+func BuffFactor(kind string) float32 {
+	for _, b := range buffs {
+		if b.Kind == kind {
+			return 1 + b.Amount
+		}
+	}
+	return 1
+}
+
+// Buffs lists the active buffs for the HUD.
+func Buffs() []Buff {
+	return buffs
+}
+
+func updateBuffs(dt float32) {
+	for i := 0; i < len(buffs); {
+		buffs[i].Time -= dt
+		if buffs[i].Time <= 0 {
+			buffs = append(buffs[:i], buffs[i+1:]...)
+		} else {
+			i++
+		}
+	}
+}
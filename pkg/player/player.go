@@ -1,16 +1,18 @@
 package player
 
 import (
-	"os"
+	"math"
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/input"
+	"spooknloot/pkg/stats"
 	"spooknloot/pkg/world"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
-	screenWidth          = 1500
-	screenHeight         = 900
-	camYOffset   float32 = 1
+	camYOffset float32 = 1
 )
 
 var (
@@ -29,17 +31,34 @@ var (
 	PlayerToolHitBox                                                         rl.Rectangle
 	playerToolFrame                                                          int
 	playerJumping                                                            bool
-	playerJumpTimer                                                          int
 	playerFrameAttack                                                        int
 	playerFrameDead                                                          int
-	frameCountAttack                                                         int
 	attackActive                                                             bool
 	baseFacing                                                               Direction
 	PlayerRadius                                                             rl.Rectangle
 
-	frameCount int
-
-	playerSpeed float32 = 1.4
+	// Animation and gameplay timers, all in seconds.
+	playTime        float32
+	animTimer       float32
+	attackAnimTimer float32
+	deathAnimTimer  float32
+	walkFrameTime   float32 = 8.0 / 60
+	idleFrameTime   float32 = 45.0 / 60
+	attackFrameTime float32 = 4.0 / 60
+	deathFrameTime  float32 = 8.0 / 60
+
+	// Speeds in pixels per second. moveScale is the share of the speed
+	// each axis gets: less on diagonals and for a stick that isn't pushed
+	// all the way.
+	moveScaleX  float32 = 1
+	moveScaleY  float32 = 1
+	walkSpeed   float32 = 84
+	dashSpeed   float32 = 120
+	playerSpeed float32 = walkSpeed
+
+	// Interpolation factor between the previous and the current tick, set
+	// before drawing.
+	renderAlpha float32 = 1
 
 	healthBarTexture rl.Texture2D
 	healthBarScale   float32 = 4
@@ -49,15 +68,13 @@ var (
 	healthBarSrc     rl.Rectangle
 
 	attackRange       float32 = 40
-	isAttacking       bool
-	attackDuration    int = 15
-	attackTimer       int
 	attackPressed     bool
 	attackHasHit      bool
-	playerDamageTimer int
+	damageDuration    float32 = 10.0 / 60
+	playerDamageTimer float32
 
-	healthRegenTimer    int = 0
-	healthRegenInterval int = 120
+	healthRegenTimer    float32 = 0
+	healthRegenInterval float32 = 2
 
 	takeDamage bool
 
@@ -71,13 +88,8 @@ var (
 	externalColliders    []rl.Rectangle
 
 	// Audio
-	attackSound        rl.Sound
-	attackSoundLoaded  bool
-	damageSound        rl.Sound
-	damageSoundLoaded  bool
-	walkingSound       rl.Sound
-	walkingSoundLoaded bool
-	lastFootstepFrame  int
+	lastFootstepTime float32
+	footstepGap      float32 = 8.0 / 60
 )
 
 type Direction int
@@ -114,96 +126,93 @@ const (
 )
 
 func InitPlayer() {
-	playerSprite = rl.LoadTexture("assets/char/char-sheet.png")
+	playerSprite = backend.Current.LoadTexture("assets/char/char-sheet.png")
 
-	healthBarTexture = rl.LoadTexture("assets/char/healthbar.png")
-	rl.SetTextureFilter(healthBarTexture, rl.FilterPoint)
+	healthBarTexture = backend.Current.LoadTexture("assets/char/healthbar.png")
+	backend.Current.SetTextureFilter(healthBarTexture, rl.FilterPoint)
 
 	healthBarSrc = rl.NewRectangle(0, 0, 64, 16)
 
 	playerSrc = rl.NewRectangle(0, 0, 48, 48)
 
 	PlayerDest = rl.NewRectangle(495, 344, 48, 48)
+	oldX, oldY = PlayerDest.X, PlayerDest.Y
 	PlayerHitBox = rl.NewRectangle(0, 0, 6, 6)
 
 	Cam = rl.NewCamera2D(
-		rl.NewVector2(float32(rl.GetScreenWidth()/2), float32(rl.GetScreenHeight()/2)),
+		rl.NewVector2(float32(backend.Current.ScreenWidth()/2), float32(backend.Current.ScreenHeight()/2)),
 		rl.NewVector2(float32(PlayerDest.X+(PlayerDest.Width/2)), float32(PlayerDest.Y+(PlayerDest.Height/2)+camYOffset)),
 		0,
 		4,
 	)
 
-	if _, err := os.Stat("assets/audio/attack.mp3"); err == nil {
-		attackSound = rl.LoadSound("assets/audio/attack.mp3")
-		rl.SetSoundVolume(attackSound, 0.6)
-		attackSoundLoaded = true
-	}
+	lastFootstepTime = -footstepGap
+}
 
-	if _, err := os.Stat("assets/audio/damage.mp3"); err == nil {
-		damageSound = rl.LoadSound("assets/audio/damage.mp3")
-		rl.SetSoundVolume(damageSound, 0.6)
-		damageSoundLoaded = true
-	}
+func DrawPlayerTexture() {
+	rl.DrawTexturePro(playerSprite, playerSrc, interpolatedDest(), rl.NewVector2(0, 0), 0, rl.White)
+}
 
-	if _, err := os.Stat("assets/audio/walking.mp3"); err == nil {
-		walkingSound = rl.LoadSound("assets/audio/walking.mp3")
-		rl.SetSoundVolume(walkingSound, 0.35)
-		walkingSoundLoaded = true
-		lastFootstepFrame = -10
-	}
+// SetInterpolation sets how far rendering is between the previous and the
+// current simulation tick (0..1) and moves the camera accordingly.
+func SetInterpolation(alpha float32) {
+	if alpha < 0 {
+		alpha = 0
+	} else if alpha > 1 {
+		alpha = 1
+	}
+	renderAlpha = alpha
+	dest := interpolatedDest()
+	Cam.Offset = rl.NewVector2(float32(backend.Current.ScreenWidth()/2), float32(backend.Current.ScreenHeight()/2))
+	Cam.Target = rl.NewVector2(float32(dest.X+(dest.Width/2)), float32(dest.Y+(dest.Height/2)+camYOffset))
 }
 
-func DrawPlayerTexture() {
-	rl.DrawTexturePro(playerSprite, playerSrc, PlayerDest, rl.NewVector2(0, 0), 0, rl.White)
+func interpolatedDest() rl.Rectangle {
+	dest := PlayerDest
+	dest.X = oldX + (PlayerDest.X-oldX)*renderAlpha
+	dest.Y = oldY + (PlayerDest.Y-oldY)*renderAlpha
+	return dest
 }
 
+// PlayerInput samples the held movement keys and latches an attack click
+// until the next PlayerMoving tick consumes it.
 func PlayerInput() {
+	playerUp, playerDown, playerLeft, playerRight = false, false, false, false
+	PlayerMove, playerJumping = false, false
+
 	// Block input while dead so the player can't move/attack during death animation
 	if IsPlayerDead() {
 		return
 	}
 	/* 	activeItem := userinterface.PlayerActiveItem */
-	if rl.IsKeyDown(rl.KeyW) || rl.IsKeyDown(rl.KeyUp) {
-		if !playerMoveTool {
-			PlayerMove = true
-			playerUp = true
-		}
-	}
-
-	if rl.IsKeyDown(rl.KeyS) || rl.IsKeyDown(rl.KeyDown) {
-		if !playerMoveTool {
-			PlayerMove = true
-			playerDown = true
-		}
-	}
-
-	if rl.IsKeyDown(rl.KeyA) || rl.IsKeyDown(rl.KeyLeft) {
-		if !playerMoveTool {
-			PlayerMove = true
-			playerLeft = true
-		}
-	}
-
-	if rl.IsKeyDown(rl.KeyD) || rl.IsKeyDown(rl.KeyRight) {
-		if !playerMoveTool {
-			PlayerMove = true
-			playerRight = true
-		}
-	}
-
-	if rl.IsKeyDown(rl.KeySpace) {
+	dir := input.Move()
+	// Keys and stick alike walk at most at full speed, diagonals included
+	if l := float32(math.Hypot(float64(dir.X), float64(dir.Y))); l > 1 {
+		dir.X /= l
+		dir.Y /= l
+	}
+	moveScaleX = float32(math.Abs(float64(dir.X)))
+	moveScaleY = float32(math.Abs(float64(dir.Y)))
+	if !playerMoveTool && (dir.X != 0 || dir.Y != 0) {
+		PlayerMove = true
+		playerUp = dir.Y < 0
+		playerDown = dir.Y > 0
+		playerLeft = dir.X < 0
+		playerRight = dir.X > 0
+	}
+
+	if input.Down(input.Jump) {
 		playerJumping = true
-		playerJumpTimer = 1
 	}
 
-	if rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift) || playerJumping {
-		playerSpeed = 2
+	if input.Down(input.Dash) || playerJumping {
+		playerSpeed = dashSpeed
 	} else {
-		playerSpeed = 1.4
+		playerSpeed = walkSpeed
 	}
 
-	if rl.IsMouseButtonPressed(rl.MouseLeftButton) {
-		if !(rl.IsKeyDown(rl.KeySpace) || rl.IsKeyDown(rl.KeyLeftShift) || rl.IsKeyDown(rl.KeyRightShift)) {
+	if input.Pressed(input.Attack) {
+		if !(input.Down(input.Jump) || input.Down(input.Dash)) {
 			playerAttack = true
 			attackPressed = true
 		}
@@ -221,7 +230,6 @@ func TryAttack(targetPos rl.Vector2, attackFunc func(float32)) bool {
 		if dist <= attackRange {
 			attackFunc(2.5)
 			attackHasHit = true
-			attackTimer = attackDuration
 			playerAttack = false
 			attackPressed = false
 			return true
@@ -231,35 +239,27 @@ func TryAttack(targetPos rl.Vector2, attackFunc func(float32)) bool {
 	return false
 }
 
-func PlayerMoving() {
+// PlayerMoving advances the player by one simulation tick of dt seconds.
+func PlayerMoving(dt float32) {
 	oldX, oldY = PlayerDest.X, PlayerDest.Y
 	playerSrc.X = playerSrc.Width * float32(playerFrame)
+	playTime += dt
 
 	if IsPlayerDead() {
-		if attackSoundLoaded && rl.IsSoundPlaying(attackSound) {
-			rl.StopSound(attackSound)
-		}
-		if damageSoundLoaded && rl.IsSoundPlaying(damageSound) {
-			rl.StopSound(damageSound)
-		}
-		if walkingSoundLoaded && rl.IsSoundPlaying(walkingSound) {
-			rl.StopSound(walkingSound)
-		}
+		audio.StopSound("attack")
+		audio.StopSound("damage")
+		audio.StopSound("walking")
 		attackActive = false
 	}
 
 	if playerAttack && !attackActive {
 		attackActive = true
 		playerFrameAttack = 0
-		frameCountAttack = 0
+		attackAnimTimer = 0
 		attackHasHit = false
-		if attackSoundLoaded && !rl.IsSoundPlaying(attackSound) {
-			rl.PlaySound(attackSound)
-		}
+		audio.PlaySound("attack")
 
-		if walkingSoundLoaded && rl.IsSoundPlaying(walkingSound) {
-			rl.StopSound(walkingSound)
-		}
+		audio.StopSound("walking")
 
 		playerDirections()
 
@@ -282,25 +282,20 @@ func PlayerMoving() {
 		default:
 			playerDir = DirDamageDown
 		}
-		playerDamageTimer = 10
+		playerDamageTimer = damageDuration
 		takeDamage = false
 
-		if damageSoundLoaded && !rl.IsSoundPlaying(damageSound) {
-			rl.PlaySound(damageSound)
-		}
-		if walkingSoundLoaded && rl.IsSoundPlaying(walkingSound) {
-			rl.StopSound(walkingSound)
-		}
+		audio.PlaySound("damage")
+		audio.StopSound("walking")
 	}
 
 	if attackActive {
-		frameCountAttack++
-		if frameCountAttack%4 == 0 {
+		attackAnimTimer += dt
+		if attackAnimTimer >= attackFrameTime {
+			attackAnimTimer -= attackFrameTime
 			playerFrameAttack++
 		}
-		if attackSoundLoaded && !rl.IsSoundPlaying(attackSound) {
-			rl.PlaySound(attackSound)
-		}
+		audio.PlaySound("attack")
 		if playerFrameAttack >= 4 {
 			attackActive = false
 			playerFrameAttack = 0
@@ -317,13 +312,15 @@ func PlayerMoving() {
 			default:
 				playerDir = DirIdleDown
 			}
-			if attackSoundLoaded && rl.IsSoundPlaying(attackSound) {
-				rl.StopSound(attackSound)
-			}
+			audio.StopSound("attack")
 		}
 	}
 
-	RegenerateHealth()
+	RegenerateHealth(dt)
+	updateBuffs(dt)
+
+	step := playerSpeed * BuffFactor("speed") * dt
+	animTimer += dt
 
 	if PlayerMove {
 		if playerUp {
@@ -331,9 +328,9 @@ func PlayerMoving() {
 				playerDir = DirMoveUp
 				baseFacing = DirMoveUp
 			}
-			PlayerDest.Y -= playerSpeed
+			PlayerDest.Y -= step * moveScaleY
 
-			if playerSpeed == 2 {
+			if playerSpeed == dashSpeed {
 				playerDir = DirDashUp
 			}
 
@@ -346,9 +343,9 @@ func PlayerMoving() {
 				playerDir = DirMoveDown
 				baseFacing = DirMoveDown
 			}
-			PlayerDest.Y += playerSpeed
+			PlayerDest.Y += step * moveScaleY
 
-			if playerSpeed == 2 {
+			if playerSpeed == dashSpeed {
 				playerDir = DirDashDown
 			}
 
@@ -361,9 +358,9 @@ func PlayerMoving() {
 				playerDir = DirMoveLeft
 				baseFacing = DirMoveLeft
 			}
-			PlayerDest.X -= playerSpeed
+			PlayerDest.X -= step * moveScaleX
 
-			if playerSpeed == 2 {
+			if playerSpeed == dashSpeed {
 				playerDir = DirDashLeft
 			}
 
@@ -377,9 +374,9 @@ func PlayerMoving() {
 				playerDir = DirMoveRight
 				baseFacing = DirMoveRight
 			}
-			PlayerDest.X += playerSpeed
+			PlayerDest.X += step * moveScaleX
 
-			if playerSpeed == 2 {
+			if playerSpeed == dashSpeed {
 				playerDir = DirDashRight
 			}
 
@@ -388,7 +385,8 @@ func PlayerMoving() {
 			}
 		}
 
-		if frameCount%8 == 1 {
+		if animTimer >= walkFrameTime {
+			animTimer -= walkFrameTime
 			if !attackActive {
 				playerFrame++
 			}
@@ -399,16 +397,11 @@ func PlayerMoving() {
 				playerFrame = 0
 			}
 
-			if walkingSoundLoaded && playerDamageTimer == 0 && !attackActive {
+			if playerDamageTimer == 0 && !attackActive {
 				if playerFrame == 0 || playerFrame == 2 {
-					if frameCount-lastFootstepFrame >= 8 { // minimal gap between steps
-						lastFootstepFrame = frameCount
-						if !rl.IsSoundPlaying(walkingSound) {
-							rl.PlaySound(walkingSound)
-						} else {
-							rl.StopSound(walkingSound)
-							rl.PlaySound(walkingSound)
-						}
+					if playTime-lastFootstepTime >= footstepGap { // minimal gap between steps
+						lastFootstepTime = playTime
+						audio.RestartSound("walking")
 					}
 				}
 			}
@@ -416,23 +409,22 @@ func PlayerMoving() {
 
 		PlayerOpenHouseDoor()
 
-	} else if frameCount%45 == 1 {
+	} else if animTimer >= idleFrameTime {
+		animTimer -= idleFrameTime
 		playerFrame++
 		if playerDamageTimer > 0 && playerFrame >= 2 {
 			playerFrame = 0
 		}
 	}
 
-	frameCount++
 	if playerDamageTimer > 0 {
-		playerDamageTimer--
-		if damageSoundLoaded && !rl.IsSoundPlaying(damageSound) {
-			rl.PlaySound(damageSound)
+		playerDamageTimer -= dt
+		if playerDamageTimer < 0 {
+			playerDamageTimer = 0
 		}
+		audio.PlaySound("damage")
 	} else {
-		if damageSoundLoaded && rl.IsSoundPlaying(damageSound) {
-			rl.StopSound(damageSound)
-		}
+		audio.StopSound("damage")
 	}
 
 	if IsPlayerDead() {
@@ -448,7 +440,9 @@ func PlayerMoving() {
 		}
 
 		if !deathAnimationComplete {
-			if frameCount%8 == 1 {
+			deathAnimTimer += dt
+			if deathAnimTimer >= deathFrameTime {
+				deathAnimTimer -= deathFrameTime
 				playerFrameDead++
 			}
 			if playerFrameDead >= 7 {
@@ -495,17 +489,12 @@ func PlayerMoving() {
 	}
 
 	if !PlayerMove {
-		if walkingSoundLoaded && rl.IsSoundPlaying(walkingSound) {
-			rl.StopSound(walkingSound)
-		}
+		audio.StopSound("walking")
 	}
 
 	// Keep camera offset centered on current window size every frame (important after fullscreen toggle)
-	Cam.Offset = rl.NewVector2(float32(rl.GetScreenWidth()/2), float32(rl.GetScreenHeight()/2))
+	Cam.Offset = rl.NewVector2(float32(backend.Current.ScreenWidth()/2), float32(backend.Current.ScreenHeight()/2))
 	Cam.Target = rl.NewVector2(float32(PlayerDest.X+(PlayerDest.Width/2)), float32(PlayerDest.Y+(PlayerDest.Height/2)+camYOffset))
-
-	PlayerMove, playerJumping = false, false
-	playerUp, playerDown, playerLeft, playerRight = false, false, false, false
 }
 
 func PlayerCollision(tiles []world.Tile) {
@@ -565,11 +554,11 @@ func PlayerOpenHouseDoor() {
 	}
 }
 
-func RegenerateHealth() {
+func RegenerateHealth(dt float32) {
 	if IsPlayerDead() {
 		return
 	}
-	healthRegenTimer++
+	healthRegenTimer += dt * BuffFactor("regen")
 
 	if healthRegenTimer >= healthRegenInterval {
 		if currentHealth < maxHealth {
@@ -604,27 +593,31 @@ func SetPlayerDamageState() bool {
 	return takeDamage
 }
 
+// TakeDamage lowers the player's health; a negative damage heals.
 func TakeDamage(damage float32) {
+	before := currentHealth
 	currentHealth -= damage
 	if currentHealth < 0 {
 		currentHealth = 0
 	}
+	if lost := before - currentHealth; lost > 0 {
+		stats.Emit(stats.Event{Kind: stats.PlayerDamaged, Amount: lost})
+	}
 
 	UpdateHealthBar()
 }
 
 func DrawHealthBar() {
 	healthBarSrc.Y = healthBarSrc.Height * float32(healthbarDir)
+	rl.DrawTexturePro(healthBarTexture, healthBarSrc, HealthBarRect(), rl.NewVector2(0, 0), 0, rl.White)
+}
 
+// HealthBarRect is where the health bar is drawn on screen, so the HUD can
+// put things below it.
+func HealthBarRect() rl.Rectangle {
 	margin := float32(10)
 	barW, barH := float32(64)*healthBarScale, float32(16)*healthBarScale
-
-	healthBarX := margin
-	healthBarY := margin
-
-	healthBarDest := rl.NewRectangle(healthBarX, healthBarY, barW, barH)
-
-	rl.DrawTexturePro(healthBarTexture, healthBarSrc, healthBarDest, rl.NewVector2(0, 0), 0, rl.White)
+	return rl.NewRectangle(margin, margin, barW, barH)
 }
 
 func SetHealthBarScale(scale float32) {
@@ -642,6 +635,13 @@ func GetMaxHealth() float32 {
 	return maxHealth
 }
 
+// SetMaxHealth sets the health a run starts with and heals the player to it.
+func SetMaxHealth(h float32) {
+	maxHealth = h
+	currentHealth = h
+	UpdateHealthBar()
+}
+
 func IsPlayerDead() bool {
 	return currentHealth <= 0
 }
@@ -659,22 +659,20 @@ func ResetPlayer() {
 	playerFrameDead = 0
 	PlayerMove = false
 	playerUp, playerDown, playerLeft, playerRight = false, false, false, false
-	isAttacking = false
-	attackTimer = 0
 	playerAttack = false
-	frameCount = 0
+	animTimer = 0
+	deathAnimTimer = 0
+	playerDamageTimer = 0
 	healthRegenTimer = 0
+	buffs = nil
 	deathAnimationComplete = false
+	oldX, oldY = PlayerDest.X, PlayerDest.Y
 
 	attackActive = false
-	if attackSoundLoaded && rl.IsSoundPlaying(attackSound) {
-		rl.StopSound(attackSound)
-	}
-	if damageSoundLoaded && rl.IsSoundPlaying(damageSound) {
-		rl.StopSound(damageSound)
-	}
+	audio.StopSound("attack")
+	audio.StopSound("damage")
 
-	Cam.Offset = rl.NewVector2(float32(rl.GetScreenWidth()/2), float32(rl.GetScreenHeight()/2))
+	Cam.Offset = rl.NewVector2(float32(backend.Current.ScreenWidth()/2), float32(backend.Current.ScreenHeight()/2))
 	Cam.Target = rl.NewVector2(float32(PlayerDest.X+(PlayerDest.Width/2)), float32(PlayerDest.Y+(PlayerDest.Height/2)+camYOffset))
 
 	UpdateHealthBar()
@@ -709,20 +707,8 @@ func playerDirections() {
 }
 
 func UnloadPlayerTexture() {
-	rl.UnloadTexture(playerSprite)
-	rl.UnloadTexture(healthBarTexture)
-	if attackSoundLoaded {
-		rl.UnloadSound(attackSound)
-		attackSoundLoaded = false
-	}
-	if damageSoundLoaded {
-		rl.UnloadSound(damageSound)
-		damageSoundLoaded = false
-	}
-	if walkingSoundLoaded {
-		rl.UnloadSound(walkingSound)
-		walkingSoundLoaded = false
-	}
+	backend.Current.UnloadTexture(playerSprite)
+	backend.Current.UnloadTexture(healthBarTexture)
 }
 
 func SetExternalColliders(rects []rl.Rectangle) {
@@ -738,7 +724,9 @@ func ClearExternalColliders() {
 func SetPosition(x, y float32) {
 	PlayerDest.X = x
 	PlayerDest.Y = y
+	// Teleport: nothing to interpolate from
+	oldX, oldY = x, y
 	// Ensure camera follows player center consistently with Y offset
-	Cam.Offset = rl.NewVector2(float32(rl.GetScreenWidth()/2), float32(rl.GetScreenHeight()/2))
+	Cam.Offset = rl.NewVector2(float32(backend.Current.ScreenWidth()/2), float32(backend.Current.ScreenHeight()/2))
 	Cam.Target = rl.NewVector2(float32(PlayerDest.X+(PlayerDest.Width/2)), float32(PlayerDest.Y+(PlayerDest.Height/2)+camYOffset))
 }
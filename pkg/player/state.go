@@ -0,0 +1,39 @@
+package player
+
+// State is the part of the player that outlives a session: where they
+// stand, which way they face and how healthy they are.
+type State struct {
+	X, Y        float32
+	Dir         Direction
+	Health      float32
+	RegenTimer  float32
+	DamageTimer float32
+	Buffs       []Buff `json:",omitempty"`
+}
+
+func Snapshot() State {
+	return State{
+		X:           PlayerDest.X,
+		Y:           PlayerDest.Y,
+		Dir:         playerDir,
+		Health:      currentHealth,
+		RegenTimer:  healthRegenTimer,
+		DamageTimer: playerDamageTimer,
+		Buffs:       append([]Buff(nil), buffs...),
+	}
+}
+
+// Restore puts the player back into a saved state.
+func Restore(s State) {
+	ResetPlayer()
+	SetPosition(s.X, s.Y)
+	playerDir = s.Dir
+	currentHealth = s.Health
+	if currentHealth > maxHealth {
+		currentHealth = maxHealth
+	}
+	healthRegenTimer = s.RegenTimer
+	playerDamageTimer = s.DamageTimer
+	buffs = append([]Buff(nil), s.Buffs...)
+	UpdateHealthBar()
+}
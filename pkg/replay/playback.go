@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"spooknloot/pkg/backend"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Playback answers the game's input queries from a replay file. Everything
+// else (resources, audio, screen size) goes to the wrapped backend.
+type Playback struct {
+	backend.Backend
+
+	seed     int64
+	bindings map[string][]string
+	frames   []frame
+	pos      int
+	cur      frame
+	mouse    rl.Vector2
+}
+
+// Load opens a replay recorded by Recorder and plays it on top of b.
+func Load(path string, b backend.Backend) (*Playback, error) {
+	seed, bindings, frames, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Playback{Backend: b, seed: seed, bindings: bindings, frames: frames}, nil
+}
+
+// Seed is the run seed the replay was recorded with.
+func (p *Playback) Seed() int64 {
+	return p.seed
+}
+
+// Bindings are the action bindings the replay was recorded with, in the
+// settings file's format.
+func (p *Playback) Bindings() map[string][]string {
+	return p.bindings
+}
+
+// NextFrame makes the next recorded frame's input current. It returns false
+// once the replay is over.
+func (p *Playback) NextFrame() bool {
+	if p.pos >= len(p.frames) {
+		p.cur = frame{}
+		return false
+	}
+	p.cur = p.frames[p.pos]
+	p.pos++
+	if p.cur.hasMouse {
+		p.mouse = p.cur.mouse
+	}
+	return true
+}
+
+// Ticks is how many simulation ticks ran after the current frame's input.
+func (p *Playback) Ticks() int {
+	return p.cur.ticks
+}
+
+// Progress reports the current frame and the total number of frames.
+func (p *Playback) Progress() (int, int) {
+	return p.pos, len(p.frames)
+}
+
+func (p *Playback) IsKeyDown(key int32) bool {
+	return contains(p.cur.down, key)
+}
+
+func (p *Playback) IsKeyPressed(key int32) bool {
+	return contains(p.cur.pressed, key)
+}
+
+func (p *Playback) IsMouseButtonDown(button rl.MouseButton) bool {
+	return contains(p.cur.held, int32(button))
+}
+
+func (p *Playback) IsMouseButtonPressed(button rl.MouseButton) bool {
+	return contains(p.cur.clicks, int32(button))
+}
+
+func (p *Playback) MousePosition() rl.Vector2 {
+	return p.mouse
+}
+
+func (p *Playback) IsGamepadAvailable(pad int32) bool {
+	return contains(p.cur.pads, pad)
+}
+
+func (p *Playback) IsGamepadButtonDown(pad, button int32) bool {
+	return contains(p.cur.padDown, padCode(pad, button))
+}
+
+func (p *Playback) IsGamepadButtonPressed(pad, button int32) bool {
+	return contains(p.cur.padPressed, padCode(pad, button))
+}
+
+func (p *Playback) GamepadAxis(pad, a int32) float32 {
+	code := padCode(pad, a)
+	for _, x := range p.cur.axes {
+		if x.code == code {
+			return x.value
+		}
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"spooknloot/pkg/backend"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Recorder wraps a backend and remembers the input the game read from it.
+// Install it with backend.Use and call EndFrame once per rendered frame.
+type Recorder struct {
+	backend.Backend
+
+	seed     int64
+	bindings map[string][]string
+	frames   []frame
+	cur      frame
+}
+
+// NewRecorder records input read from b for a run started with seed.
+// Frames hold raw keys, so the player's action bindings are stored too.
+func NewRecorder(b backend.Backend, seed int64, bindings map[string][]string) *Recorder {
+	return &Recorder{Backend: b, seed: seed, bindings: bindings}
+}
+
+func (r *Recorder) IsKeyDown(key int32) bool {
+	down := r.Backend.IsKeyDown(key)
+	if down {
+		r.cur.down = addUnique(r.cur.down, key)
+	}
+	return down
+}
+
+func (r *Recorder) IsKeyPressed(key int32) bool {
+	pressed := r.Backend.IsKeyPressed(key)
+	if pressed {
+		r.cur.pressed = addUnique(r.cur.pressed, key)
+	}
+	return pressed
+}
+
+func (r *Recorder) IsMouseButtonDown(button rl.MouseButton) bool {
+	down := r.Backend.IsMouseButtonDown(button)
+	if down {
+		r.cur.held = addUnique(r.cur.held, int32(button))
+	}
+	return down
+}
+
+func (r *Recorder) IsMouseButtonPressed(button rl.MouseButton) bool {
+	pressed := r.Backend.IsMouseButtonPressed(button)
+	if pressed {
+		r.cur.clicks = addUnique(r.cur.clicks, int32(button))
+	}
+	return pressed
+}
+
+func (r *Recorder) MousePosition() rl.Vector2 {
+	pos := r.Backend.MousePosition()
+	r.cur.hasMouse = true
+	r.cur.mouse = pos
+	return pos
+}
+
+func (r *Recorder) IsGamepadAvailable(pad int32) bool {
+	ok := r.Backend.IsGamepadAvailable(pad)
+	if ok {
+		r.cur.pads = addUnique(r.cur.pads, pad)
+	}
+	return ok
+}
+
+func (r *Recorder) IsGamepadButtonDown(pad, button int32) bool {
+	down := r.Backend.IsGamepadButtonDown(pad, button)
+	if down {
+		r.cur.padDown = addUnique(r.cur.padDown, padCode(pad, button))
+	}
+	return down
+}
+
+func (r *Recorder) IsGamepadButtonPressed(pad, button int32) bool {
+	pressed := r.Backend.IsGamepadButtonPressed(pad, button)
+	if pressed {
+		r.cur.padPressed = addUnique(r.cur.padPressed, padCode(pad, button))
+	}
+	return pressed
+}
+
+func (r *Recorder) GamepadAxis(pad, a int32) float32 {
+	v := r.Backend.GamepadAxis(pad, a)
+	if v == 0 {
+		return v
+	}
+	code := padCode(pad, a)
+	for _, x := range r.cur.axes {
+		if x.code == code {
+			return v
+		}
+	}
+	r.cur.axes = append(r.cur.axes, axis{code: code, value: v})
+	return v
+}
+
+// EndFrame stores this frame's input together with the number of
+// simulation ticks that ran after it.
+func (r *Recorder) EndFrame(ticks int) {
+	r.cur.ticks = ticks
+	r.frames = append(r.frames, r.cur)
+	r.cur = frame{}
+}
+
+// Frames is the number of frames recorded so far.
+func (r *Recorder) Frames() int {
+	return len(r.frames)
+}
+
+// Save writes everything recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return save(path, r.seed, r.bindings, r.frames)
+}
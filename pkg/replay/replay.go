@@ -0,0 +1,298 @@
+// Package replay records the input the game reads each frame and plays it
+// back through the same backend calls, so a seeded run can be reproduced
+// exactly.
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+const (
+	magic   = "SNLR"
+	version = 3
+)
+
+// frame is the input of one rendered frame: every key and mouse button the
+// game saw down (held) or pressed (clicks), the mouse position if it was
+// read, and how many simulation ticks ran afterwards.
+type frame struct {
+	ticks    int
+	down     []int32
+	pressed  []int32
+	held     []int32
+	clicks   []int32
+	hasMouse bool
+	mouse    rl.Vector2
+
+	// Gamepad input; codes are pad<<8 | button (or axis).
+	pads       []int32
+	padDown    []int32
+	padPressed []int32
+	axes       []axis
+}
+
+type axis struct {
+	code  int32
+	value float32
+}
+
+func padCode(pad, n int32) int32 {
+	return pad<<8 | n
+}
+
+func contains(list []int32, v int32) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func addUnique(list []int32, v int32) []int32 {
+	if contains(list, v) {
+		return list
+	}
+	return append(list, v)
+}
+
+// Replay file layout, gzip compressed:
+//
+//	"SNLR" version(byte) seed(int64)
+//	#bindings, (action, #keys, keys...)... (strings as uvarint length + bytes)
+//	frames(uvarint)
+//	per frame: ticks, #down, down..., #pressed, pressed..., #held, held...,
+//	           #clicks, clicks... (all uvarint), mouse flag(byte)
+//	           [x y (float32 bits)], #pads, pads..., #padDown, padDown...,
+//	           #padPressed, padPressed..., #axes, (code, value bits)...
+func save(path string, seed int64, bindings map[string][]string, frames []frame) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	w := bufio.NewWriter(zw)
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(buf, v)
+		w.Write(buf[:n])
+	}
+	putList := func(list []int32) {
+		putUvarint(uint64(len(list)))
+		for _, v := range list {
+			putUvarint(uint64(uint32(v)))
+		}
+	}
+	putString := func(v string) {
+		putUvarint(uint64(len(v)))
+		w.WriteString(v)
+	}
+
+	w.WriteString(magic)
+	w.WriteByte(version)
+	binary.Write(w, binary.LittleEndian, seed)
+	actions := make([]string, 0, len(bindings))
+	for a := range bindings {
+		actions = append(actions, a)
+	}
+	sort.Strings(actions)
+	putUvarint(uint64(len(actions)))
+	for _, a := range actions {
+		putString(a)
+		putUvarint(uint64(len(bindings[a])))
+		for _, k := range bindings[a] {
+			putString(k)
+		}
+	}
+	putUvarint(uint64(len(frames)))
+	for _, fr := range frames {
+		putUvarint(uint64(fr.ticks))
+		putList(fr.down)
+		putList(fr.pressed)
+		putList(fr.held)
+		putList(fr.clicks)
+		if fr.hasMouse {
+			w.WriteByte(1)
+			binary.Write(w, binary.LittleEndian, math.Float32bits(fr.mouse.X))
+			binary.Write(w, binary.LittleEndian, math.Float32bits(fr.mouse.Y))
+		} else {
+			w.WriteByte(0)
+		}
+		putList(fr.pads)
+		putList(fr.padDown)
+		putList(fr.padPressed)
+		putUvarint(uint64(len(fr.axes)))
+		for _, a := range fr.axes {
+			putUvarint(uint64(uint32(a.code)))
+			putUvarint(uint64(math.Float32bits(a.value)))
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func load(path string) (int64, map[string][]string, []frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	r := bufio.NewReader(zr)
+
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, nil, nil, err
+	}
+	if string(head[:len(magic)]) != magic {
+		return 0, nil, nil, errors.New("replay: not a replay file")
+	}
+	if head[len(magic)] != version {
+		return 0, nil, nil, fmt.Errorf("replay: unsupported version %d", head[len(magic)])
+	}
+
+	var seed int64
+	if err := binary.Read(r, binary.LittleEndian, &seed); err != nil {
+		return 0, nil, nil, err
+	}
+	readList := func() ([]int32, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		var list []int32
+		for i := uint64(0); i < n; i++ {
+			v, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, int32(uint32(v)))
+		}
+		return list, nil
+	}
+
+	readString := func() (string, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", err
+		}
+		if n > 1<<10 {
+			return "", errors.New("replay: corrupt header")
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	actions, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	bindings := map[string][]string{}
+	for i := uint64(0); i < actions; i++ {
+		a, err := readString()
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		for j := uint64(0); j < n; j++ {
+			k, err := readString()
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			bindings[a] = append(bindings[a], k)
+		}
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	frames := make([]frame, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var fr frame
+		ticks, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		fr.ticks = int(ticks)
+		if fr.down, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		if fr.pressed, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		if fr.held, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		if fr.clicks, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		flag, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if flag == 1 {
+			var x, y uint32
+			binary.Read(r, binary.LittleEndian, &x)
+			if err := binary.Read(r, binary.LittleEndian, &y); err != nil {
+				return 0, nil, nil, err
+			}
+			fr.hasMouse = true
+			fr.mouse = rl.NewVector2(math.Float32frombits(x), math.Float32frombits(y))
+		}
+		if fr.pads, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		if fr.padDown, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		if fr.padPressed, err = readList(); err != nil {
+			return 0, nil, nil, err
+		}
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		for j := uint64(0); j < n; j++ {
+			code, err := binary.ReadUvarint(r)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			bits, err := binary.ReadUvarint(r)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			fr.axes = append(fr.axes, axis{code: int32(uint32(code)), value: math.Float32frombits(uint32(bits))})
+		}
+		frames = append(frames, fr)
+	}
+	return seed, bindings, frames, nil
+}
@@ -0,0 +1,100 @@
+package replay
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"spooknloot/pkg/backend"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// inputs is what the game reads from a backend in one frame.
+type inputs struct {
+	keysDown, keysPressed [3]bool
+	mouseDown, mouseClick bool
+	mouse                 rl.Vector2
+	pad                   bool
+	padDown, padPressed   bool
+	axisX, axisY          float32
+}
+
+var keys = [3]int32{rl.KeyW, rl.KeyD, rl.KeySpace}
+
+func read(b backend.Backend) inputs {
+	var in inputs
+	for i, k := range keys {
+		in.keysDown[i] = b.IsKeyDown(k)
+		in.keysPressed[i] = b.IsKeyPressed(k)
+	}
+	in.mouseDown = b.IsMouseButtonDown(rl.MouseButtonLeft)
+	in.mouseClick = b.IsMouseButtonPressed(rl.MouseButtonLeft)
+	in.mouse = b.MousePosition()
+	in.pad = b.IsGamepadAvailable(0)
+	in.padDown = b.IsGamepadButtonDown(0, rl.GamepadButtonRightFaceDown)
+	in.padPressed = b.IsGamepadButtonPressed(0, rl.GamepadButtonRightFaceDown)
+	in.axisX = b.GamepadAxis(0, rl.GamepadAxisLeftX)
+	in.axisY = b.GamepadAxis(0, rl.GamepadAxisLeftY)
+	return in
+}
+
+func TestRecordAndPlayBack(t *testing.T) {
+	const seed = 1234
+	bindings := map[string][]string{"Attack": {"K", "PadA"}, "Dash": {"Q"}}
+
+	h := backend.NewHeadless(640, 360)
+	rec := NewRecorder(h, seed, bindings)
+	var want []inputs
+	var ticks []int
+	for i := 0; i < 200; i++ {
+		h.SetKeyDown(rl.KeyW, i%4 < 2)
+		h.SetKeyDown(rl.KeyD, i%7 == 3)
+		if i%11 == 0 {
+			h.PressKey(rl.KeySpace)
+		}
+		h.SetMouseButtonDown(rl.MouseButtonLeft, i%5 == 0)
+		if i%13 == 0 {
+			h.Click(rl.MouseButtonLeft, rl.NewVector2(float32(i), float32(2*i)+0.5))
+		}
+		if i >= 50 {
+			h.SetGamepadButtonDown(rl.GamepadButtonRightFaceDown, i%3 == 0)
+			h.SetGamepadAxis(rl.GamepadAxisLeftX, float32(i%9-4)/4)
+			h.SetGamepadAxis(rl.GamepadAxisLeftY, -0.25)
+		}
+
+		want = append(want, read(rec))
+		ticks = append(ticks, i%3)
+		rec.EndFrame(i % 3)
+		h.EndFrame()
+	}
+
+	path := filepath.Join(t.TempDir(), "run.rep")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path, backend.NewHeadless(640, 360))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Seed() != seed {
+		t.Errorf("seed %d, want %d", p.Seed(), seed)
+	}
+	if !reflect.DeepEqual(p.Bindings(), bindings) {
+		t.Errorf("bindings %v, want %v", p.Bindings(), bindings)
+	}
+	for i := range want {
+		if !p.NextFrame() {
+			t.Fatalf("replay ended after %d of %d frames", i, len(want))
+		}
+		if p.Ticks() != ticks[i] {
+			t.Errorf("frame %d: %d ticks, want %d", i, p.Ticks(), ticks[i])
+		}
+		if got := read(p); got != want[i] {
+			t.Errorf("frame %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+	if p.NextFrame() {
+		t.Error("replay has more frames than were recorded")
+	}
+}
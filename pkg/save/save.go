@@ -0,0 +1,129 @@
+// Package save stores an in-progress run as versioned JSON in the user's
+// config directory so it can be continued after quitting.
+package save
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"spooknloot/pkg/dungeon"
+	"spooknloot/pkg/mobs"
+	"spooknloot/pkg/player"
+	"spooknloot/pkg/stats"
+)
+
+// Version is bumped whenever Run changes incompatibly. Files with another
+// version are ignored rather than half-restored.
+const Version = 1
+
+// Run is everything needed to put a run back exactly where it was left.
+type Run struct {
+	Version int   `json:"version"`
+	Seed    int64 `json:"seed"`
+	// Difficulty is the preset ID, see package difficulty.
+	Difficulty string `json:"difficulty,omitempty"`
+	// Endless, NewGamePlus and Rewards are the run mode, see package game.
+	Endless     bool           `json:"endless,omitempty"`
+	NewGamePlus int            `json:"newGamePlus,omitempty"`
+	Rewards     map[string]int `json:"rewards,omitempty"`
+	// Daily is the date of a daily challenge run.
+	Daily string `json:"daily,omitempty"`
+
+	// Scene is "town", "dungeon" or "boss".
+	Scene             string  `json:"scene"`
+	DungeonsCleared   int     `json:"dungeonsCleared"`
+	DungeonSpawnCount int     `json:"dungeonSpawnCount"`
+	WorldX            float32 `json:"worldX"`
+	WorldY            float32 `json:"worldY"`
+
+	// Timers of the dungeon exit, only used when Scene is "dungeon".
+	ExitCooldown    float32 `json:"exitCooldown"`
+	ExitSoundPlayed bool    `json:"exitSoundPlayed"`
+	MobsClearTime   float32 `json:"mobsClearTime"`
+
+	Player  player.State   `json:"player"`
+	Mobs    mobs.State     `json:"mobs"`
+	Dungeon *dungeon.State `json:"dungeon,omitempty"`
+	Stats   stats.Run      `json:"stats"`
+}
+
+// Path is where the save file lives, e.g. ~/.config/spooknloot/save.json.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spooknloot", "save.json"), nil
+}
+
+// Exists reports whether there is a save of the current version.
+func Exists() bool {
+	path, err := Path()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var head struct {
+		Version int `json:"version"`
+	}
+	return json.Unmarshal(data, &head) == nil && head.Version == Version
+}
+
+// Write stores r, replacing any previous save. The file is written to a
+// temporary name first so a crash never leaves a truncated save behind.
+func Write(r *Run) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	r.Version = Version
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func Read() (*Run, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	if r.Version != Version {
+		return nil, fmt.Errorf("save: unsupported version %d", r.Version)
+	}
+	return &r, nil
+}
+
+// Delete forgets the saved run, e.g. after the player died.
+func Delete() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package scene
+
+// Scene is one self-contained part of the game (town, a dungeon level, the
+// boss arena, a menu, ...). Only the scene on top of the stack receives input
+// and updates. Update is called once per fixed simulation tick of dt seconds.
+type Scene interface {
+	Enter()
+	Exit()
+	HandleInput()
+	Update(dt float32)
+	Draw()
+}
+
+// Overlay is implemented by scenes that are drawn on top of the scene below
+// them instead of replacing it (menus, win screen).
+type Overlay interface {
+	IsOverlay() bool
+}
+
+type Manager struct {
+	stack []Scene
+}
+
+// Push enters s on top of the current scene. The covered scene is paused,
+// not exited.
+func (m *Manager) Push(s Scene) {
+	m.stack = append(m.stack, s)
+	s.Enter()
+}
+
+// Pop exits the top scene and resumes the one below it.
+func (m *Manager) Pop() {
+	if len(m.stack) == 0 {
+		return
+	}
+	top := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	top.Exit()
+}
+
+// Switch replaces the top scene with s.
+func (m *Manager) Switch(s Scene) {
+	m.Pop()
+	m.Push(s)
+}
+
+// Reset exits every scene on the stack and starts over with s.
+func (m *Manager) Reset(s Scene) {
+	for len(m.stack) > 0 {
+		m.Pop()
+	}
+	m.Push(s)
+}
+
+func (m *Manager) Current() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// At returns the scene at index i, 0 being the bottom of the stack.
+func (m *Manager) At(i int) Scene {
+	return m.stack[i]
+}
+
+func (m *Manager) Len() int {
+	return len(m.stack)
+}
+
+func (m *Manager) HandleInput() {
+	if s := m.Current(); s != nil {
+		s.HandleInput()
+	}
+}
+
+func (m *Manager) Update(dt float32) {
+	if s := m.Current(); s != nil {
+		s.Update(dt)
+	}
+}
+
+// Draw renders the top scene and, for overlays, every scene beneath it down
+// to the first non-overlay scene.
+func (m *Manager) Draw() {
+	if len(m.stack) == 0 {
+		return
+	}
+	first := len(m.stack) - 1
+	for first > 0 {
+		o, ok := m.stack[first].(Overlay)
+		if !ok || !o.IsOverlay() {
+			break
+		}
+		first--
+	}
+	for i := first; i < len(m.stack); i++ {
+		m.stack[i].Draw()
+	}
+}
@@ -0,0 +1,207 @@
+// Package settings holds the player's preferences. They are read from
+// settings.json in the OS config directory and can be overridden for one
+// session with command-line flags.
+package settings
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+)
+
+type Settings struct {
+	Width      int  `json:"width"`
+	Height     int  `json:"height"`
+	Fullscreen bool `json:"fullscreen"`
+
+	// Volumes are 0..1 and scale each sound's own mix level. Master
+	// applies to music and sound effects alike.
+	MasterVolume float32 `json:"masterVolume"`
+	MusicVolume  float32 `json:"musicVolume"`
+	SoundVolume  float32 `json:"soundVolume"`
+	MusicMuted   bool    `json:"musicMuted"`
+
+	// Bindings maps action names to key names, see package input. Only
+	// actions that differ from the defaults are listed.
+	Bindings map[string][]string `json:"bindings,omitempty"`
+
+	// Language is the code of the text catalog, e.g. "de". Empty follows
+	// the system language.
+	Language string `json:"language,omitempty"`
+
+	// Difficulty is the ID of the preset new runs start with, see package
+	// difficulty. It remembers the last choice in the start menu.
+	Difficulty string `json:"difficulty,omitempty"`
+
+	// Seed fixes the run seed, 0 picks a new one for every run.
+	Seed  int64 `json:"seed,omitempty"`
+	Debug bool  `json:"debug,omitempty"`
+}
+
+func Defaults() Settings {
+	return Settings{
+		Width:        1500,
+		Height:       900,
+		MasterVolume: 1,
+		MusicVolume:  1,
+		SoundVolume:  1,
+	}
+}
+
+var (
+	// Current is what the game runs with: the file plus flag overrides.
+	Current = Defaults()
+
+	// stored is what the file holds; flag overrides never end up in it.
+	stored = Defaults()
+	// loaded is set by Load. Tools that never load the file (headless
+	// simulation) run on defaults and never write it either.
+	loaded bool
+
+	flags struct {
+		fs           *flag.FlagSet
+		width        int
+		height       int
+		fullscreen   bool
+		masterVolume float64
+		musicVolume  float64
+		soundVolume  float64
+		seed         int64
+		debug        bool
+		lang         string
+		difficulty   string
+	}
+)
+
+// Path is where the settings file lives, e.g. ~/.config/spooknloot/settings.json.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spooknloot", "settings.json"), nil
+}
+
+// Load reads the settings file. A missing file is not an error; the
+// defaults are used instead.
+func Load() error {
+	s := Defaults()
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &s)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.clamp()
+	stored = s
+	Current = s
+	loaded = true
+	return nil
+}
+
+// Save writes the stored settings back to the file.
+func Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Change applies fn to the current and the stored settings and saves them,
+// for preferences changed in-game (fullscreen, music toggle, ...).
+func Change(fn func(s *Settings)) error {
+	fn(&Current)
+	fn(&stored)
+	Current.clamp()
+	stored.clamp()
+	if !loaded {
+		return nil
+	}
+	return Save()
+}
+
+// RegisterFlags adds the command-line overrides to fs. Call ApplyFlags
+// after parsing and after Load.
+func RegisterFlags(fs *flag.FlagSet) {
+	d := Defaults()
+	flags.fs = fs
+	fs.IntVar(&flags.width, "width", d.Width, "window width")
+	fs.IntVar(&flags.height, "height", d.Height, "window height")
+	fs.BoolVar(&flags.fullscreen, "fullscreen", false, "start in borderless fullscreen")
+	fs.Float64Var(&flags.masterVolume, "master-volume", float64(d.MasterVolume), "overall volume 0..1")
+	fs.Float64Var(&flags.musicVolume, "music-volume", float64(d.MusicVolume), "music volume 0..1")
+	fs.Float64Var(&flags.soundVolume, "sound-volume", float64(d.SoundVolume), "sound effect volume 0..1")
+	fs.Int64Var(&flags.seed, "seed", 0, "run seed; the same seed generates the same dungeons (0 = random)")
+	fs.BoolVar(&flags.debug, "debug", false, "show the debug overlay")
+	fs.StringVar(&flags.lang, "lang", "", "language code, e.g. en or de")
+	fs.StringVar(&flags.difficulty, "difficulty", "", "difficulty of new runs: easy, normal, hard or nightmare")
+}
+
+// ApplyFlags copies every flag that was given on the command line into
+// Current. The settings file is left alone.
+func ApplyFlags() {
+	if flags.fs == nil {
+		return
+	}
+	flags.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "width":
+			Current.Width = flags.width
+		case "height":
+			Current.Height = flags.height
+		case "fullscreen":
+			Current.Fullscreen = flags.fullscreen
+		case "master-volume":
+			Current.MasterVolume = float32(flags.masterVolume)
+		case "music-volume":
+			Current.MusicVolume = float32(flags.musicVolume)
+		case "sound-volume":
+			Current.SoundVolume = float32(flags.soundVolume)
+		case "seed":
+			Current.Seed = flags.seed
+		case "debug":
+			Current.Debug = flags.debug
+		case "lang":
+			Current.Language = flags.lang
+		case "difficulty":
+			Current.Difficulty = flags.difficulty
+		}
+	})
+	Current.clamp()
+}
+
+func (s *Settings) clamp() {
+	if s.Width < 320 {
+		s.Width = 320
+	}
+	if s.Height < 240 {
+		s.Height = 240
+	}
+	s.MasterVolume = clamp01(s.MasterVolume)
+	s.MusicVolume = clamp01(s.MusicVolume)
+	s.SoundVolume = clamp01(s.SoundVolume)
+}
+
+func clamp01(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
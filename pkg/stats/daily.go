@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// dailyDays is how many days of daily challenge results are kept.
+const dailyDays = 30
+
+// DailyScores are the best runs of each daily challenge, keyed by the
+// challenge's date and sorted best first.
+type DailyScores map[string][]Score
+
+// Add enters s into the table of the challenge on date and reports whether
+// it made it. A boss kill beats any depth, ties go to the faster run.
+func (d DailyScores) Add(date string, s Score) bool {
+	var in bool
+	d[date], in = insert(d[date], s, func(a, b Score) bool {
+		if a.BossKill != b.BossKill {
+			return a.BossKill
+		}
+		if a.Depth != b.Depth {
+			return a.Depth > b.Depth
+		}
+		return a.Time < b.Time
+	})
+
+	// Forget the oldest challenges; the dates sort like the days
+	if len(d) > dailyDays {
+		dates := make([]string, 0, len(d))
+		for date := range d {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		for _, old := range dates[:len(dates)-dailyDays] {
+			delete(d, old)
+		}
+	}
+	return in
+}
+
+// DailyScoresPath is where the daily results live, next to the high scores.
+func DailyScoresPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spooknloot", "daily.json"), nil
+}
+
+// LoadDailyScores reads the daily results. A missing file is an empty table.
+func LoadDailyScores() (DailyScores, error) {
+	d := DailyScores{}
+	path, err := DailyScoresPath()
+	if err != nil {
+		return d, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	err = json.Unmarshal(data, &d)
+	if d == nil {
+		d = DailyScores{}
+	}
+	return d, err
+}
+
+func SaveDailyScores(d DailyScores) error {
+	path, err := DailyScoresPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
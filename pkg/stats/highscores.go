@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// tableSize is how many entries each high-score table keeps.
+const tableSize = 5
+
+// Score is one finished run.
+type Score struct {
+	Depth    int     `json:"depth"`
+	Time     float32 `json:"time"`
+	Kills    int     `json:"kills"`
+	BossKill bool    `json:"bossKill,omitempty"`
+	Seed     int64   `json:"seed"`
+	// Date is the day the run ended, YYYY-MM-DD.
+	Date string `json:"date"`
+}
+
+// HighScores are the best finished runs, each table sorted best first.
+type HighScores struct {
+	Depth    []Score `json:"depth"`
+	BossTime []Score `json:"bossTime"`
+	Kills    []Score `json:"kills"`
+}
+
+// Add enters s into every table it qualifies for and reports whether it
+// made it into at least one.
+func (h *HighScores) Add(s Score) bool {
+	var in [3]bool
+	h.Depth, in[0] = insert(h.Depth, s, func(a, b Score) bool {
+		if a.Depth != b.Depth {
+			return a.Depth > b.Depth
+		}
+		return a.Time < b.Time
+	})
+	if s.BossKill {
+		h.BossTime, in[1] = insert(h.BossTime, s, func(a, b Score) bool {
+			return a.Time < b.Time
+		})
+	}
+	h.Kills, in[2] = insert(h.Kills, s, func(a, b Score) bool {
+		return a.Kills > b.Kills
+	})
+	return in[0] || in[1] || in[2]
+}
+
+// insert puts s into table behind every entry that is at least as good.
+func insert(table []Score, s Score, better func(a, b Score) bool) ([]Score, bool) {
+	i := sort.Search(len(table), func(i int) bool { return better(s, table[i]) })
+	if i >= tableSize {
+		return table, false
+	}
+	table = append(table, Score{})
+	copy(table[i+1:], table[i:])
+	table[i] = s
+	if len(table) > tableSize {
+		table = table[:tableSize]
+	}
+	return table, true
+}
+
+// HighScoresPath is where the high scores live, next to the settings file.
+func HighScoresPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "spooknloot", "highscores.json"), nil
+}
+
+// LoadHighScores reads the high-score file. A missing file is an empty table.
+func LoadHighScores() (HighScores, error) {
+	var h HighScores
+	path, err := HighScoresPath()
+	if err != nil {
+		return h, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err
+}
+
+func SaveHighScores(h HighScores) error {
+	path, err := HighScoresPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
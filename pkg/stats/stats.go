@@ -0,0 +1,111 @@
+// Package stats keeps track of what happens during a run. The gameplay
+// packages report events with Emit; the current run's totals are built from
+// them and other packages (high scores, achievements) can listen in.
+package stats
+
+// Kind says what an Event reports.
+type Kind int
+
+const (
+	// MobDamaged: a mob of MobType lost Amount health.
+	MobDamaged Kind = iota
+	// MobKilled: a mob of MobType died.
+	MobKilled
+	// PlayerDamaged: the player lost Amount health.
+	PlayerDamaged
+	// PotionDrunk: the player drank a potion that healed Amount.
+	PotionDrunk
+	// LevelEntered: the player reached dungeon level Level (the boss arena
+	// counts as the level after the last one).
+	LevelEntered
+	// BossKilled: the boss died, the run is won.
+	BossKilled
+	// PlayerDied: the run is lost.
+	PlayerDied
+	// EasterEggFound: the player found the town secret called Name.
+	EasterEggFound
+)
+
+type Event struct {
+	Kind    Kind
+	MobType string
+	Name    string
+	Amount  float32
+	Level   int
+}
+
+// Run is the tally of the current run.
+type Run struct {
+	Time        float32        `json:"time"`
+	Depth       int            `json:"depth"`
+	Kills       map[string]int `json:"kills"`
+	DamageDealt float32        `json:"damageDealt"`
+	DamageTaken float32        `json:"damageTaken"`
+	Potions     int            `json:"potions"`
+	BossKilled  bool           `json:"bossKilled"`
+}
+
+// TotalKills sums the kills of every mob type.
+func (r Run) TotalKills() int {
+	n := 0
+	for _, k := range r.Kills {
+		n += k
+	}
+	return n
+}
+
+var (
+	run       = Run{Kills: map[string]int{}}
+	listeners []func(Event)
+)
+
+// Emit records e in the current run and passes it on to every listener.
+func Emit(e Event) {
+	switch e.Kind {
+	case MobDamaged:
+		run.DamageDealt += e.Amount
+	case MobKilled:
+		run.Kills[e.MobType]++
+	case PlayerDamaged:
+		run.DamageTaken += e.Amount
+	case PotionDrunk:
+		run.Potions++
+	case LevelEntered:
+		if e.Level > run.Depth {
+			run.Depth = e.Level
+		}
+	case BossKilled:
+		run.BossKilled = true
+	}
+	for _, fn := range listeners {
+		fn(e)
+	}
+}
+
+// Subscribe calls fn for every event from now on.
+func Subscribe(fn func(Event)) {
+	listeners = append(listeners, fn)
+}
+
+// Advance adds dt seconds of play time to the current run.
+func Advance(dt float32) {
+	run.Time += dt
+}
+
+// Current returns the tally of the current run.
+func Current() Run {
+	return run
+}
+
+// Reset starts a new tally.
+func Reset() {
+	run = Run{Kills: map[string]int{}}
+}
+
+// Restore continues the tally of a saved run.
+func Restore(r Run) {
+	run = r
+	if run.Kills == nil {
+		run.Kills = map[string]int{}
+	}
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"spooknloot/pkg/i18n"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// BindingRow is one line of the rebinding screen.
+type BindingRow struct {
+	Label string
+	Keys  string
+}
+
+// row rects computed each frame DrawBindingsScreen is called
+var bindingRowRects []rl.Rectangle
+
+// DrawBindingsScreen lists every action with its keys. The selected row is
+// highlighted; while waiting for a new key its keys are replaced by "...".
+func DrawBindingsScreen(rows []BindingRow, selected int, waiting bool, hint string) {
+	w := rl.GetScreenWidth()
+	h := rl.GetScreenHeight()
+	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 220))
+
+	title := i18n.T("bindings.title")
+	titleSize := float32(48)
+	rowSize := float32(24)
+	rowGap := float32(10)
+	colGap := float32(40)
+	cx := float32(w) / 2
+
+	textColor := rl.NewColor(134, 87, 87, 255)
+
+	totalHeight := titleSize + 30 + float32(len(rows))*(rowSize+rowGap) + 30 + rowSize
+	y := float32(h)/2 - totalHeight/2
+
+	if menuFontLoaded {
+		tw := rl.MeasureTextEx(menuFont, title, titleSize, 0)
+		rl.DrawTextEx(menuFont, title, rl.NewVector2(cx-(tw.X/2), y), titleSize, 0, rl.RayWhite)
+	} else {
+		tw := rl.MeasureText(title, int32(titleSize))
+		rl.DrawText(title, int32(cx)-tw/2, int32(y), int32(titleSize), rl.RayWhite)
+	}
+	y += titleSize + 30
+
+	bindingRowRects = bindingRowRects[:0]
+	rowW := float32(640)
+	for i, row := range rows {
+		rect := rl.NewRectangle(cx-rowW/2, y-rowGap/2, rowW, rowSize+rowGap)
+		bindingRowRects = append(bindingRowRects, rect)
+
+		color := textColor
+		keys := row.Keys
+		if i == selected {
+			rl.DrawRectangleRec(rect, rl.NewColor(255, 255, 255, 30))
+			color = rl.RayWhite
+			if waiting {
+				keys = "..."
+			}
+		}
+		lw := rl.MeasureText(row.Label, int32(rowSize))
+		rl.DrawText(row.Label, int32(cx-colGap/2)-lw, int32(y), int32(rowSize), color)
+		rl.DrawText(keys, int32(cx+colGap/2), int32(y), int32(rowSize), color)
+		y += rowSize + rowGap
+	}
+
+	y += 30 - rowGap
+	hw := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, int32(cx)-hw/2, int32(y), 20, textColor)
+}
+
+// GetBindingRowRects returns the rows of the last drawn rebinding screen
+// for mouse hit testing.
+func GetBindingRowRects() []rl.Rectangle {
+	return bindingRowRects
+}
@@ -0,0 +1,15 @@
+package ui
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DrawBuffs lists active buffs, one per line, from (x, y) down.
+func DrawBuffs(lines []string, x, y float32) {
+	size := int32(20)
+	for i, line := range lines {
+		ly := int32(y) + int32(i)*(size+4)
+		rl.DrawText(line, int32(x)+1, ly+1, size, rl.Black)
+		rl.DrawText(line, int32(x), ly, size, rl.NewColor(230, 190, 80, 255))
+	}
+}
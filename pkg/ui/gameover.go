@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"spooknloot/pkg/i18n"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// SummaryLine is one row of the run summary. Indented rows break down the
+// row above them.
+type SummaryLine struct {
+	Label  string
+	Value  string
+	Indent bool
+}
+
+// item rects computed each frame a summary screen is drawn
+var gameOverItemRects []rl.Rectangle
+
+// DrawGameOver shows the run summary in two columns with the choices below.
+func DrawGameOver(lines []SummaryLine, items []string, selected int) {
+	DrawSummaryScreen(i18n.T("gameover.title"), lines, items, selected)
+}
+
+// DrawSummaryScreen is the game-over layout with any title: two columns of
+// lines with the choices below.
+func DrawSummaryScreen(title string, lines []SummaryLine, items []string, selected int) {
+	w := rl.GetScreenWidth()
+	h := rl.GetScreenHeight()
+	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 200))
+
+	titleSize := float32(64)
+	lineSize := float32(22)
+	lineGap := float32(8)
+	itemSize := float32(30)
+	itemGap := float32(12)
+	blockGap := float32(36)
+	colGap := float32(40)
+	cx := float32(w) / 2
+
+	textColor := rl.NewColor(134, 87, 87, 255)
+
+	totalHeight := titleSize + blockGap + float32(len(lines))*(lineSize+lineGap) + blockGap + float32(len(items))*(itemSize+itemGap)
+	y := float32(h)/2 - totalHeight/2
+
+	if menuFontLoaded {
+		tw := rl.MeasureTextEx(menuFont, title, titleSize, 0)
+		rl.DrawTextEx(menuFont, title, rl.NewVector2(cx-(tw.X/2), y), titleSize, 0, rl.RayWhite)
+	} else {
+		tw := rl.MeasureText(title, int32(titleSize))
+		rl.DrawText(title, int32(cx)-tw/2, int32(y), int32(titleSize), rl.RayWhite)
+	}
+	y += titleSize + blockGap
+
+	for _, line := range lines {
+		size := lineSize
+		if line.Indent {
+			size = lineSize - 4
+		}
+		lw := rl.MeasureText(line.Label, int32(size))
+		rl.DrawText(line.Label, int32(cx-colGap/2)-lw, int32(y), int32(size), textColor)
+		rl.DrawText(line.Value, int32(cx+colGap/2), int32(y), int32(size), rl.RayWhite)
+		y += lineSize + lineGap
+	}
+
+	y += blockGap - lineGap
+	gameOverItemRects, _ = drawItems(gameOverItemRects[:0], items, selected, cx, y, itemSize, itemGap, textColor)
+}
+
+// GetGameOverItemRects returns the choices of the last drawn game-over or
+// summary screen for mouse hit testing.
+func GetGameOverItemRects() []rl.Rectangle {
+	return gameOverItemRects
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"spooknloot/pkg/i18n"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// ScoreTable is one column of the high-score screen. Each row shows its
+// Label with the Value (the date) below it.
+type ScoreTable struct {
+	Title string
+	Rows  []SummaryLine
+}
+
+// DrawHighScores shows the tables side by side.
+func DrawHighScores(tables []ScoreTable, hint string) {
+	w := rl.GetScreenWidth()
+	h := rl.GetScreenHeight()
+	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 220))
+
+	title := i18n.T("highscores.title")
+	titleSize := float32(48)
+	headSize := float32(26)
+	rowSize := float32(22)
+	dateSize := float32(16)
+	rowGap := float32(12)
+	blockGap := float32(30)
+	cx := float32(w) / 2
+
+	textColor := rl.NewColor(134, 87, 87, 255)
+
+	maxRows := 0
+	for _, t := range tables {
+		maxRows = max(maxRows, len(t.Rows))
+	}
+	maxRows = max(maxRows, 1)
+	totalHeight := titleSize + blockGap + headSize + rowGap + float32(maxRows)*(rowSize+dateSize+rowGap) + blockGap + 20
+	y := float32(h)/2 - totalHeight/2
+
+	if menuFontLoaded {
+		tw := rl.MeasureTextEx(menuFont, title, titleSize, 0)
+		rl.DrawTextEx(menuFont, title, rl.NewVector2(cx-(tw.X/2), y), titleSize, 0, rl.RayWhite)
+	} else {
+		tw := rl.MeasureText(title, int32(titleSize))
+		rl.DrawText(title, int32(cx)-tw/2, int32(y), int32(titleSize), rl.RayWhite)
+	}
+	y += titleSize + blockGap
+
+	colW := float32(w) / float32(len(tables)+1)
+	for i, t := range tables {
+		colX := colW * float32(i+1)
+		ty := y
+		hw := rl.MeasureText(t.Title, int32(headSize))
+		rl.DrawText(t.Title, int32(colX)-hw/2, int32(ty), int32(headSize), rl.RayWhite)
+		ty += headSize + rowGap
+
+		if len(t.Rows) == 0 {
+			nw := rl.MeasureText("-", int32(rowSize))
+			rl.DrawText("-", int32(colX)-nw/2, int32(ty), int32(rowSize), textColor)
+		}
+		for _, row := range t.Rows {
+			lw := rl.MeasureText(row.Label, int32(rowSize))
+			rl.DrawText(row.Label, int32(colX)-lw/2, int32(ty), int32(rowSize), textColor)
+			ty += rowSize
+			dw := rl.MeasureText(row.Value, int32(dateSize))
+			rl.DrawText(row.Value, int32(colX)-dw/2, int32(ty), int32(dateSize), textColor)
+			ty += dateSize + rowGap
+		}
+	}
+	y += headSize + rowGap + float32(maxRows)*(rowSize+dateSize+rowGap) + blockGap
+
+	hintW := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, int32(cx)-hintW/2, int32(y), 20, textColor)
+}
@@ -4,6 +4,10 @@ import (
 	"os"
 	"strings"
 
+	"spooknloot/pkg/backend"
+	"spooknloot/pkg/i18n"
+	"spooknloot/pkg/input"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -13,16 +17,22 @@ var (
 	menuFont         rl.Font
 	menuFontLoaded   bool
 	menuTitleOffsetY float32
+	runSeed          int64
+
+	// Selectable entries drawn below the instructions, see SetMenuItems.
+	menuItems     []string
+	menuSelected  int
+	menuItemRects []rl.Rectangle
 
-	// boss win overlay button rect (computed each frame DrawBossWinOverlay is called)
-	bossWinButtonRect rl.Rectangle
+	// boss win overlay button rects (computed each frame DrawBossWinOverlay is called)
+	bossWinItemRects []rl.Rectangle
 )
 
 func InitMenu(texturePath string) {
 	menuTexLoaded = false
 	menuFontLoaded = false
 	if _, err := os.Stat(texturePath); err == nil {
-		t := rl.LoadTexture(texturePath)
+		t := backend.Current.LoadTexture(texturePath)
 		if t.ID != 0 {
 			menuTex = t
 			menuTexLoaded = true
@@ -31,7 +41,7 @@ func InitMenu(texturePath string) {
 
 	const defaultFontPath = "assets/ui/FantasyRPGtext.ttf"
 	if _, err := os.Stat(defaultFontPath); err == nil {
-		f := rl.LoadFontEx(defaultFontPath, 64, nil, 0)
+		f := backend.Current.LoadFont(defaultFontPath, 64)
 		if f.BaseSize != 0 {
 			menuFont = f
 			menuFontLoaded = true
@@ -41,11 +51,11 @@ func InitMenu(texturePath string) {
 
 func UnloadMenu() {
 	if menuTexLoaded && menuTex.ID != 0 {
-		rl.UnloadTexture(menuTex)
+		backend.Current.UnloadTexture(menuTex)
 		menuTexLoaded = false
 	}
 	if menuFontLoaded && menuFont.BaseSize != 0 {
-		rl.UnloadFont(menuFont)
+		backend.Current.UnloadFont(menuFont)
 		menuFontLoaded = false
 	}
 }
@@ -54,6 +64,24 @@ func SetMenuTitleOffsetY(offset float32) {
 	menuTitleOffsetY = offset
 }
 
+// SetRunSeed sets the seed shown at the bottom of the menu.
+func SetRunSeed(seed int64) {
+	runSeed = seed
+}
+
+// SetMenuItems sets the entries the menu offers (e.g. "Continue", "New Game")
+// and which one is highlighted. No items hides the list.
+func SetMenuItems(items []string, selected int) {
+	menuItems = items
+	menuSelected = selected
+}
+
+// GetMenuItemRects returns the screen rectangles of the menu entries as
+// drawn in the last frame, for mouse hit testing.
+func GetMenuItemRects() []rl.Rectangle {
+	return menuItemRects
+}
+
 func DrawMenuOverlay() {
 	w := rl.GetScreenWidth()
 	h := rl.GetScreenHeight()
@@ -79,10 +107,14 @@ func DrawMenuOverlay() {
 		rl.DrawTexturePro(menuTex, src, dst, origin, 0, rl.RayWhite)
 	}
 
-	title := "SPOOK 'N LOOT"
-	description := "A game by joeel56\nYour goal is to kill all enemies and reach the exit\n of the dungeon.\nYou have 20 levels and every level gets harder\ntill you reach the boss.\nIf you die you start from the beginning."
-	instructions := "Press ESC to open the menu\nYou can walk with WASD or arrow keys\nAttack the enemies with left click\nYou can pause the music with F7\nF10 to toggle fullscreen"
-	smallTextBottom := "Assets by franuka.art"
+	title := i18n.T("menu.title")
+	description := i18n.T("menu.description")
+	instructions := i18n.T("menu.help.pause", input.Describe(input.Pause)) + "\n" +
+		i18n.T("menu.help.walk", input.Describe(input.MoveUp), input.Describe(input.MoveLeft), input.Describe(input.MoveDown), input.Describe(input.MoveRight)) + "\n" +
+		i18n.T("menu.help.attack", input.Describe(input.Attack)) + "\n" +
+		i18n.T("menu.help.music", input.Describe(input.ToggleMusic)) + "\n" +
+		i18n.T("menu.help.fullscreen", input.Describe(input.ToggleFullscreen))
+	smallTextBottom := i18n.T("menu.credits") + "\n" + i18n.T("menu.seed", runSeed)
 	smallTextBottomSize := float32(16)
 	smallTextBottomLines := strings.Split(smallTextBottom, "\n")
 	smallTextBottomHeight := float32(0)
@@ -110,7 +142,12 @@ func DrawMenuOverlay() {
 	if len(instLines) > 0 {
 		instHeight = float32(len(instLines))*bodySize + float32(max(0, len(instLines)-1))*lineGap
 	}
-	totalHeight := titleSize + blockGap + descHeight + blockGap + instHeight + smallTextBottomHeight
+	itemSize := float32(28)
+	itemsHeight := float32(0)
+	if len(menuItems) > 0 {
+		itemsHeight = blockGap + float32(len(menuItems))*(itemSize+lineGap)
+	}
+	totalHeight := titleSize + blockGap + descHeight + blockGap + instHeight + itemsHeight + smallTextBottomHeight
 	startY := cy - (totalHeight / 2)
 
 	textColor := rl.NewColor(134, 87, 87, 255)
@@ -135,6 +172,12 @@ func DrawMenuOverlay() {
 			rl.DrawText(line, int32(cx)-int32(lw/2), int32(y), int32(bodySize), textColor)
 			y += bodySize + lineGap
 		}
+		menuItemRects = menuItemRects[:0]
+		if len(menuItems) > 0 {
+			y += blockGap
+			menuItemRects, y = drawItems(menuItemRects, menuItems, menuSelected, cx, y, itemSize, lineGap, textColor)
+		}
+
 		y += blockGap - lineGap
 		for _, line := range smallTextBottomLines {
 			lw := rl.MeasureText(line, int32(smallTextBottomSize))
@@ -144,20 +187,20 @@ func DrawMenuOverlay() {
 	}
 }
 
-// DrawBossWinOverlay draws a centered message and a button to restart the game.
-// The button rectangle can be queried via GetBossWinButtonRect.
-func DrawBossWinOverlay() {
+// DrawBossWinOverlay draws a centered message with one button per item
+// below it; the selected one is highlighted. The button rectangles can be
+// queried via GetBossWinItemRects.
+func DrawBossWinOverlay(title string, items []string, selected int) {
 	w := rl.GetScreenWidth()
 	h := rl.GetScreenHeight()
 	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 220))
 
-	title := "You've succeeded. Congrats! Want another try?"
-	buttonText := "Starte das Spiel neu"
-
 	// Layout
 	titleSize := float32(36)
+	buttonSize := float32(24)
 	buttonPaddingX := float32(24)
 	buttonPaddingY := float32(10)
+	buttonGap := float32(12)
 	spacing := float32(0)
 	cx := float32(w) / 2
 	cy := float32(h) / 2
@@ -171,45 +214,76 @@ func DrawBossWinOverlay() {
 		rl.DrawText(title, int32(cx)-tw/2, int32(cy-60), 24, rl.RayWhite)
 	}
 
-	// Button sizing (use default font for simplicity if custom not loaded)
-	var bw, bh float32
-	if menuFontLoaded {
-		sz := float32(24)
-		tw := rl.MeasureTextEx(menuFont, buttonText, sz, 0)
-		bw = tw.X + buttonPaddingX*2
-		bh = sz + buttonPaddingY*2
-	} else {
-		sz := int32(24)
-		tw := float32(rl.MeasureText(buttonText, sz))
-		bw = tw + buttonPaddingX*2
-		bh = float32(sz) + buttonPaddingY*2
+	// Buttons share the width of the widest label
+	measure := func(text string) float32 {
+		if menuFontLoaded {
+			return rl.MeasureTextEx(menuFont, text, buttonSize, 0).X
+		}
+		return float32(rl.MeasureText(text, int32(buttonSize)))
+	}
+	var bw float32
+	for _, item := range items {
+		bw = float32(max(int(bw), int(measure(item)+buttonPaddingX*2)))
 	}
-	bx := cx - (bw / 2)
+	bh := buttonSize + buttonPaddingY*2
+
+	bossWinItemRects = bossWinItemRects[:0]
 	by := cy + 10
+	for i, item := range items {
+		rect := rl.NewRectangle(cx-bw/2, by, bw, bh)
+		bossWinItemRects = append(bossWinItemRects, rect)
 
-	bossWinButtonRect = rl.NewRectangle(bx, by, bw, bh)
-	// Button background
-	rl.DrawRectangleRec(bossWinButtonRect, rl.NewColor(200, 200, 200, 255))
-	rl.DrawRectangleLines(int32(bossWinButtonRect.X), int32(bossWinButtonRect.Y), int32(bossWinButtonRect.Width), int32(bossWinButtonRect.Height), rl.Black)
+		bg := rl.NewColor(200, 200, 200, 255)
+		if i == selected {
+			bg = rl.RayWhite
+		}
+		rl.DrawRectangleRec(rect, bg)
+		rl.DrawRectangleLines(int32(rect.X), int32(rect.Y), int32(rect.Width), int32(rect.Height), rl.Black)
+		if i == selected {
+			rl.DrawRectangleLinesEx(rect, 3, rl.NewColor(134, 87, 87, 255))
+		}
 
-	// Button label
-	if menuFontLoaded {
-		sz := float32(24)
-		tw := rl.MeasureTextEx(menuFont, buttonText, sz, 0)
-		tx := bossWinButtonRect.X + (bossWinButtonRect.Width-tw.X)/2
-		ty := bossWinButtonRect.Y + (bossWinButtonRect.Height-sz)/2
-		rl.DrawTextEx(menuFont, buttonText, rl.NewVector2(tx, ty), sz, 0, rl.Black)
-	} else {
-		sz := int32(24)
-		tw := rl.MeasureText(buttonText, sz)
-		tx := bossWinButtonRect.X + (bossWinButtonRect.Width-float32(tw))/2
-		ty := bossWinButtonRect.Y + (bossWinButtonRect.Height-float32(sz))/2
-		rl.DrawText(buttonText, int32(tx), int32(ty), sz, rl.Black)
+		// Button label
+		tw := measure(item)
+		tx := rect.X + (rect.Width-tw)/2
+		ty := rect.Y + (rect.Height-buttonSize)/2
+		if menuFontLoaded {
+			rl.DrawTextEx(menuFont, item, rl.NewVector2(tx, ty), buttonSize, 0, rl.Black)
+		} else {
+			rl.DrawText(item, int32(tx), int32(ty), int32(buttonSize), rl.Black)
+		}
+		by += bh + buttonGap
 	}
+
+	hint := input.Describe(input.Confirm)
+	hw := rl.MeasureText(hint, 18)
+	rl.DrawText(hint, int32(cx)-hw/2, int32(by), 18, rl.RayWhite)
+}
+
+// GetBossWinItemRects returns the buttons of the last drawn win overlay for
+// mouse hit testing.
+func GetBossWinItemRects() []rl.Rectangle {
+	return bossWinItemRects
 }
 
-func GetBossWinButtonRect() rl.Rectangle {
-	return bossWinButtonRect
+// drawItems draws selectable entries centered on cx, one per line starting
+// at y. It appends their rectangles to rects and returns them together with
+// the y below the last entry.
+func drawItems(rects []rl.Rectangle, items []string, selected int, cx, y, size, gap float32, color rl.Color) ([]rl.Rectangle, float32) {
+	for i, item := range items {
+		label := item
+		c := color
+		if i == selected {
+			label = "> " + item + " <"
+			c = rl.RayWhite
+		}
+		lw := rl.MeasureText(label, int32(size))
+		rect := rl.NewRectangle(cx-float32(lw)/2, y, float32(lw), size)
+		rects = append(rects, rect)
+		rl.DrawText(label, int32(rect.X), int32(y), int32(size), c)
+		y += size + gap
+	}
+	return rects, y
 }
 
 func max(a, b int) int {
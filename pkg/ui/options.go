@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"spooknloot/pkg/i18n"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// OptionRow is one line of the settings screen. Sliders show Fraction (0..1)
+// as a bar next to Value, every other row only shows Value.
+type OptionRow struct {
+	Label    string
+	Value    string
+	Slider   bool
+	Fraction float32
+}
+
+// row and slider rects computed each frame DrawOptionsScreen is called
+var (
+	optionRowRects    []rl.Rectangle
+	optionSliderRects []rl.Rectangle
+)
+
+// DrawOptionsScreen lists the settings with their current values and
+// highlights the selected row.
+func DrawOptionsScreen(rows []OptionRow, selected int, hint string) {
+	w := rl.GetScreenWidth()
+	h := rl.GetScreenHeight()
+	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 220))
+
+	title := i18n.T("options.title")
+	titleSize := float32(48)
+	rowSize := float32(24)
+	rowGap := float32(14)
+	colGap := float32(40)
+	sliderW := float32(200)
+	cx := float32(w) / 2
+
+	textColor := rl.NewColor(134, 87, 87, 255)
+
+	totalHeight := titleSize + 30 + float32(len(rows))*(rowSize+rowGap) + 30 + rowSize
+	y := float32(h)/2 - totalHeight/2
+
+	if menuFontLoaded {
+		tw := rl.MeasureTextEx(menuFont, title, titleSize, 0)
+		rl.DrawTextEx(menuFont, title, rl.NewVector2(cx-(tw.X/2), y), titleSize, 0, rl.RayWhite)
+	} else {
+		tw := rl.MeasureText(title, int32(titleSize))
+		rl.DrawText(title, int32(cx)-tw/2, int32(y), int32(titleSize), rl.RayWhite)
+	}
+	y += titleSize + 30
+
+	optionRowRects = optionRowRects[:0]
+	optionSliderRects = optionSliderRects[:0]
+	rowW := float32(640)
+	for i, row := range rows {
+		rect := rl.NewRectangle(cx-rowW/2, y-rowGap/2, rowW, rowSize+rowGap)
+		optionRowRects = append(optionRowRects, rect)
+
+		color := textColor
+		if i == selected {
+			rl.DrawRectangleRec(rect, rl.NewColor(255, 255, 255, 30))
+			color = rl.RayWhite
+		}
+		lw := rl.MeasureText(row.Label, int32(rowSize))
+		rl.DrawText(row.Label, int32(cx-colGap/2)-lw, int32(y), int32(rowSize), color)
+
+		vx := cx + colGap/2
+		var slider rl.Rectangle
+		if row.Slider {
+			slider = rl.NewRectangle(vx, y+4, sliderW, rowSize-8)
+			rl.DrawRectangleRec(slider, rl.NewColor(0, 0, 0, 120))
+			fill := slider
+			fill.Width *= row.Fraction
+			rl.DrawRectangleRec(fill, color)
+			rl.DrawRectangleLines(int32(slider.X), int32(slider.Y), int32(slider.Width), int32(slider.Height), color)
+			vx += sliderW + 16
+		}
+		optionSliderRects = append(optionSliderRects, slider)
+		rl.DrawText(row.Value, int32(vx), int32(y), int32(rowSize), color)
+		y += rowSize + rowGap
+	}
+
+	y += 30 - rowGap
+	hw := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, int32(cx)-hw/2, int32(y), 20, textColor)
+}
+
+// GetOptionRowRects returns the rows of the last drawn settings screen for
+// mouse hit testing.
+func GetOptionRowRects() []rl.Rectangle {
+	return optionRowRects
+}
+
+// GetOptionSliderRects returns the slider bar of every row, an empty
+// rectangle for rows without one.
+func GetOptionSliderRects() []rl.Rectangle {
+	return optionSliderRects
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// item rects computed each frame DrawPauseMenu is called
+var pauseItemRects []rl.Rectangle
+
+// DrawPauseMenu dims the game and lists the pause menu entries under a
+// title, with a hint line at the bottom.
+func DrawPauseMenu(title string, items []string, selected int, hint string) {
+	w := rl.GetScreenWidth()
+	h := rl.GetScreenHeight()
+	rl.DrawRectangle(0, 0, int32(w), int32(h), rl.NewColor(0, 0, 0, 200))
+
+	titleSize := float32(56)
+	itemSize := float32(32)
+	itemGap := float32(14)
+	blockGap := float32(40)
+	cx := float32(w) / 2
+
+	textColor := rl.NewColor(134, 87, 87, 255)
+
+	totalHeight := titleSize + blockGap + float32(len(items))*(itemSize+itemGap) + blockGap
+	y := float32(h)/2 - totalHeight/2
+
+	if menuFontLoaded {
+		tw := rl.MeasureTextEx(menuFont, title, titleSize, 0)
+		rl.DrawTextEx(menuFont, title, rl.NewVector2(cx-(tw.X/2), y), titleSize, 0, rl.RayWhite)
+	} else {
+		tw := rl.MeasureText(title, int32(titleSize))
+		rl.DrawText(title, int32(cx)-tw/2, int32(y), int32(titleSize), rl.RayWhite)
+	}
+	y += titleSize + blockGap
+
+	pauseItemRects, y = drawItems(pauseItemRects[:0], items, selected, cx, y, itemSize, itemGap, textColor)
+
+	y += blockGap - itemGap
+	hw := rl.MeasureText(hint, 20)
+	rl.DrawText(hint, int32(cx)-hw/2, int32(y), 20, textColor)
+}
+
+// GetPauseItemRects returns the entries of the last drawn pause menu for
+// mouse hit testing.
+func GetPauseItemRects() []rl.Rectangle {
+	return pauseItemRects
+}
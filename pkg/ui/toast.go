@@ -0,0 +1,25 @@
+package ui
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// DrawToast draws a notification box in the top right corner. alpha (0..1)
+// fades it in and out.
+func DrawToast(title, text string, alpha float32) {
+	w := rl.GetScreenWidth()
+
+	titleSize := int32(22)
+	textSize := int32(18)
+	padding := float32(12)
+	margin := float32(16)
+
+	boxW := float32(max(int(rl.MeasureText(title, titleSize)), int(rl.MeasureText(text, textSize)))) + padding*2
+	boxH := float32(titleSize+textSize) + padding*2 + 6
+	box := rl.NewRectangle(float32(w)-boxW-margin, margin, boxW, boxH)
+
+	rl.DrawRectangleRec(box, rl.Fade(rl.NewColor(41, 29, 43, 255), 0.9*alpha))
+	rl.DrawRectangleLinesEx(box, 2, rl.Fade(rl.NewColor(134, 87, 87, 255), alpha))
+	rl.DrawText(title, int32(box.X+padding), int32(box.Y+padding), titleSize, rl.Fade(rl.RayWhite, alpha))
+	rl.DrawText(text, int32(box.X+padding), int32(box.Y+padding)+titleSize+6, textSize, rl.Fade(rl.NewColor(200, 170, 170, 255), alpha))
+}
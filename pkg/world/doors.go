@@ -1,33 +1,25 @@
 package world
 
 import (
-	"os"
+	"spooknloot/pkg/audio"
+	"spooknloot/pkg/backend"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 var (
-	frameCountDoor  int
-	frameDoor       int = 0
-	doorsSprite     rl.Texture2D
-	HouseDoorSrc    rl.Rectangle
-	HouseDoorDest   rl.Rectangle
-	DoorsMaxFrame   int = 5
-	openSound       rl.Sound
-	openSoundLoaded bool
+	frameCountDoor int
+	frameDoor      int = 0
+	doorsSprite    rl.Texture2D
+	HouseDoorSrc   rl.Rectangle
+	HouseDoorDest  rl.Rectangle
+	DoorsMaxFrame  int = 5
 )
 
 func InitDoors() {
-	doorsSprite = rl.LoadTexture("assets/world/door.png")
+	doorsSprite = backend.Current.LoadTexture("assets/world/door.png")
 	HouseDoorSrc = rl.NewRectangle(0, 0, 32, 32)
 	HouseDoorDest = rl.NewRectangle(504, 175, 32, 32)
-
-	// Load door open sound if available
-	if _, err := os.Stat("assets/audio/open.mp3"); err == nil {
-		openSound = rl.LoadSound("assets/audio/open.mp3")
-		rl.SetSoundVolume(openSound, 0.7)
-		openSoundLoaded = true
-	}
 }
 
 func OpenHouseDoor() {
@@ -43,8 +35,8 @@ func OpenHouseDoor() {
 
 	HouseDoorSrc.X = float32(frameDoor * 32)
 
-	if openSoundLoaded && prev == 0 && frameDoor == 1 && !rl.IsSoundPlaying(openSound) {
-		rl.PlaySound(openSound)
+	if prev == 0 && frameDoor == 1 {
+		audio.PlaySound("door")
 	}
 }
 
@@ -53,15 +45,9 @@ func DrawDoors() {
 }
 
 func UnloadDoorsTextures() {
-	rl.UnloadTexture(doorsSprite)
-	if openSoundLoaded {
-		rl.UnloadSound(openSound)
-		openSoundLoaded = false
-	}
+	backend.Current.UnloadTexture(doorsSprite)
 }
 
 func PlayDoorOpenSound() {
-	if openSoundLoaded && !rl.IsSoundPlaying(openSound) {
-		rl.PlaySound(openSound)
-	}
+	audio.PlaySound("door")
 }
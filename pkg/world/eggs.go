@@ -0,0 +1,28 @@
+package world
+
+import (
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// EasterEgg is a hidden spot in the town, found by walking into Area.
+type EasterEgg struct {
+	Name string
+	Area rl.Rectangle
+}
+
+// EasterEggs are in map pixels; the achievements refer to them by name.
+var EasterEggs = []EasterEgg{
+	{Name: "garden", Area: rl.NewRectangle(9*16, 26*16, 6*16, 6*16)},
+	{Name: "maze", Area: rl.NewRectangle(54*16, 33*16, 3*16, 2*16)},
+	{Name: "alley", Area: rl.NewRectangle(23*16, 9*16, 16, 4*16)},
+}
+
+// EasterEggAt returns the egg whose area the hitbox touches.
+func EasterEggAt(hitbox rl.Rectangle) (EasterEgg, bool) {
+	for _, e := range EasterEggs {
+		if rl.CheckCollisionRecs(hitbox, e.Area) {
+			return e, true
+		}
+	}
+	return EasterEgg{}, false
+}
@@ -1,6 +1,8 @@
 package world
 
 import (
+	"spooknloot/pkg/backend"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -17,7 +19,7 @@ var (
 )
 
 func InitLamps() {
-	lampsSprite = rl.LoadTexture("assets/world/lamppost.png")
+	lampsSprite = backend.Current.LoadTexture("assets/world/lamppost.png")
 	LampSrcTop = rl.NewRectangle(80, 0, 16, 16)
 	LampSrcBottom = rl.NewRectangle(80, 16, 16, 16)
 	LampDestBottom = rl.NewRectangle(500, 350, 16, 16)
@@ -1,6 +1,8 @@
 package world
 
 import (
+	"spooknloot/pkg/backend"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -19,8 +21,8 @@ var (
 )
 
 func InitPumpkinLamps() {
-	pumpkinLampsSprite = rl.LoadTexture("assets/world/pumpkinlamp.png")
-	cauldronSprite = rl.LoadTexture("assets/world/Cauldron.png")
+	pumpkinLampsSprite = backend.Current.LoadTexture("assets/world/pumpkinlamp.png")
+	cauldronSprite = backend.Current.LoadTexture("assets/world/Cauldron.png")
 	PumpkinLampDest = rl.NewRectangle(500, 350, 16, 16)
 
 	cauldronDest = rl.NewRectangle(800, 255, 16, 16)
@@ -64,6 +66,6 @@ func DrawCauldron() {
 }
 
 func UnloadPumpkinLamps() {
-	rl.UnloadTexture(pumpkinLampsSprite)
-	rl.UnloadTexture(cauldronSprite)
+	backend.Current.UnloadTexture(pumpkinLampsSprite)
+	backend.Current.UnloadTexture(cauldronSprite)
 }
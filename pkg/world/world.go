@@ -6,6 +6,8 @@ import (
 	"os"
 	"strconv"
 
+	"spooknloot/pkg/backend"
+
 	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
@@ -61,16 +63,13 @@ func LoadMap(mapFile string) {
 	byteValue, _ := ioutil.ReadAll(file)
 
 	json.Unmarshal(byteValue, &WorldMap)
-}
-
-func InitWorld() {
-	SpritesheetMap = rl.LoadTexture("assets/world/spritesheet.png")
-	tileDest = rl.NewRectangle(0, 0, 16, 16)
-	tileSrc = rl.NewRectangle(0, 0, 16, 16)
 
+	assignLayers()
 }
 
-func DrawWorld() {
+// assignLayers picks the named layers out of WorldMap so collision and
+// spawning work without anything having been drawn yet.
+func assignLayers() {
 	for i := 0; i < len(WorldMap.Layers); i++ {
 		if WorldMap.Layers[i].Name == "out" {
 			Out = WorldMap.Layers[i].Tiles
@@ -123,7 +122,16 @@ func DrawWorld() {
 			Markets = WorldMap.Layers[i].Tiles
 		}
 	}
+}
 
+func InitWorld() {
+	SpritesheetMap = backend.Current.LoadTexture("assets/world/spritesheet.png")
+	tileDest = rl.NewRectangle(0, 0, 16, 16)
+	tileSrc = rl.NewRectangle(0, 0, 16, 16)
+
+}
+
+func DrawWorld() {
 	rl.DrawTexturePro(tex, tileSrc, tileDest, rl.NewVector2(0, 0), 0, rl.White)
 
 	RenderLayer(Out)
@@ -163,5 +171,5 @@ func RenderLayer(Layer []Tile) {
 }
 
 func UnloadWorldTexture() {
-	rl.UnloadTexture(SpritesheetMap)
+	backend.Current.UnloadTexture(SpritesheetMap)
 }